@@ -0,0 +1,240 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sync"
+	"time"
+
+	s "go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/zap"
+)
+
+type (
+	// HealthCheck returns an error if a dependency is not ready.
+	HealthCheck func() error
+
+	// Health runs the readiness checks of a process. Liveness only
+	// tells that the process serves HTTP requests.
+	Health struct {
+		sync.Mutex
+		checks map[string]HealthCheck
+	}
+
+	// HealthStatus models the response of the readiness endpoint.
+	HealthStatus struct {
+		Status string
+		Checks map[string]*CheckStatus
+	}
+
+	// CheckStatus models the result of a readiness check.
+	CheckStatus struct {
+		OK       bool
+		Error    string `json:",omitempty"`
+		Duration time.Duration
+	}
+
+	// BuildInfo models the version of the running binary.
+	BuildInfo struct {
+		Version   string
+		Commit    string
+		BuildTime string
+		GoVersion string
+	}
+
+	// WorkerStatus models the workers polling a task list.
+	WorkerStatus struct {
+		TaskList  string
+		Workflows bool
+		Activity  bool
+		StartedAt time.Time
+	}
+)
+
+// Build information set with -ldflags "-X" by the Makefile.
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// checkTimeout bounds the time taken by a readiness check.
+const checkTimeout = time.Second * 5
+
+// NewHealth returns a Health without checks, which is always ready.
+func NewHealth() *Health {
+	return &Health{
+		checks: make(map[string]HealthCheck),
+	}
+}
+
+// AddCheck adds a readiness check under the name.
+func (h *Health) AddCheck(name string, check HealthCheck) {
+	h.Lock()
+	defer h.Unlock()
+	h.checks[name] = check
+}
+
+// Check runs the readiness checks concurrently, the checks
+// taking longer than the check timeout fail.
+func (h *Health) Check() *HealthStatus {
+	h.Lock()
+	checks := make(map[string]HealthCheck, len(h.checks))
+	for name, check := range h.checks {
+		checks[name] = check
+	}
+	h.Unlock()
+
+	status := &HealthStatus{
+		Status: "ok",
+		Checks: make(map[string]*CheckStatus, len(checks)),
+	}
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check HealthCheck) {
+			defer wg.Done()
+			result := runCheck(check)
+			lock.Lock()
+			defer lock.Unlock()
+			status.Checks[name] = result
+			if !result.OK {
+				status.Status = "unavailable"
+			}
+		}(name, check)
+	}
+	wg.Wait()
+	return status
+}
+
+func runCheck(check HealthCheck) *CheckStatus {
+	start := time.Now()
+	errc := make(chan error, 1)
+	go func() {
+		errc <- check()
+	}()
+
+	var err error
+	select {
+	case err = <-errc:
+	case <-time.After(checkTimeout):
+		err = fmt.Errorf("timed out after %v", checkTimeout)
+	}
+	result := &CheckStatus{OK: err == nil, Duration: time.Since(start)}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// ServeLiveness responds to /healthz while the process serves requests.
+func (h *Health) ServeLiveness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, "ok")
+}
+
+// ServeReadiness responds to /readyz with the result of the checks,
+// with a 503 status if any of them failed.
+func (h *Health) ServeReadiness(w http.ResponseWriter, r *http.Request) {
+	status := h.Check()
+	w.Header().Set("Content-Type", "application/json")
+	if status.Status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(status)
+}
+
+// ServeBuildInfo responds with the build information of the binary.
+func ServeBuildInfo(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&BuildInfo{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	})
+}
+
+// HandlePprof registers the pprof handlers under /debug/pprof/ on the mux.
+func HandlePprof(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+}
+
+// NewAdminMux returns the mux of the admin endpoints: /healthz, /readyz,
+// /buildinfo and pprof.
+func NewAdminMux(health *Health) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", health.ServeLiveness)
+	mux.HandleFunc("/readyz", health.ServeReadiness)
+	mux.HandleFunc("/buildinfo", ServeBuildInfo)
+	HandlePprof(mux)
+	return mux
+}
+
+// StartAdminServer serves the admin endpoints on addr in the background,
+// unless addr is empty.
+func (h *Runtime) StartAdminServer(addr string, health *Health) {
+	if len(addr) == 0 {
+		return
+	}
+	go func() {
+		h.Logger.Info("Starting admin server", zap.String("addr", addr))
+		if err := http.ListenAndServe(addr, NewAdminMux(health)); err != nil {
+			h.Logger.Error("Admin server failed", zap.Error(err))
+		}
+	}()
+}
+
+// CadenceCheck returns a check that the cadence frontend is reachable
+// and the domain exists.
+func (h *Runtime) CadenceCheck() HealthCheck {
+	client, err := h.Builder.BuildCadenceDomainClient()
+	if err != nil {
+		return func() error { return err }
+	}
+	return func() error {
+		_, _, err := client.Describe(h.Config.DomainName)
+		if _, ok := err.(*s.EntityNotExistsError); ok {
+			return fmt.Errorf("domain %q does not exist", h.Config.DomainName)
+		}
+		if err != nil {
+			return fmt.Errorf("cadence frontend %s unreachable: %v", h.Config.HostNameAndPort, err)
+		}
+		return nil
+	}
+}
+
+// WorkersCheck returns a check that the workers of the process started
+// polling their task lists.
+func (h *Runtime) WorkersCheck() HealthCheck {
+	return func() error {
+		if len(h.Workers()) == 0 {
+			return fmt.Errorf("no workers started")
+		}
+		return nil
+	}
+}
+
+// HTTPCheck returns a check that the URL responds with a 2xx status.
+func HTTPCheck(url string) HealthCheck {
+	client := &http.Client{Timeout: checkTimeout}
+	return func() error {
+		rsp, err := client.Get(url)
+		if err != nil {
+			return err
+		}
+		rsp.Body.Close()
+		if rsp.StatusCode/100 != 2 {
+			return fmt.Errorf("%s responded %s", url, rsp.Status)
+		}
+		return nil
+	}
+}
@@ -3,6 +3,9 @@ package common
 import (
 	"fmt"
 	"io/ioutil"
+	"net"
+	"sync"
+	"time"
 
 	"go.uber.org/cadence"
 	m "go.uber.org/cadence/.gen/go/cadence"
@@ -16,6 +19,10 @@ import (
 
 const (
 	configFile = "config/development.yaml"
+
+	// DefaultWebserverAddr is the listen address of the eats webserver
+	// when none is configured.
+	DefaultWebserverAddr = ":8090"
 )
 
 type (
@@ -26,18 +33,118 @@ type (
 		Logger  *zap.Logger
 		Config  Configuration
 		Builder *WorkflowClientBuilder
+
+		workersLock sync.Mutex
+		workers     []*WorkerStatus
 	}
 
 	// Configuration for running samples.
 	Configuration struct {
-		DomainName      string `yaml:"domain"`
-		ServiceName     string `yaml:"service"`
-		HostNameAndPort string `yaml:"host"`
+		DomainName      string           `yaml:"domain"`
+		ServiceName     string           `yaml:"service"`
+		HostNameAndPort string           `yaml:"host"`
+		Store           StoreConfig      `yaml:"store"`
+		Restaurant      RestaurantConfig `yaml:"restaurant"`
+		Loyalty         LoyaltyConfig    `yaml:"loyalty"`
+		Auth            AuthConfig       `yaml:"auth"`
+		Webserver       WebserverConfig  `yaml:"webserver"`
+		Admin           AdminConfig      `yaml:"admin"`
+	}
+
+	// StoreConfig selects the store used by the eats webserver.
+	StoreConfig struct {
+		Type string `yaml:"type"` // memory or file
+		Path string `yaml:"path"` // directory of the file store
+	}
+
+	// RestaurantConfig models the opening hours and capacity of the
+	// restaurant, orders beyond it are queued or rejected depending on
+	// OverCapacity, and the channels new orders are delivered through.
+	RestaurantConfig struct {
+		MaxPreparing     int               `yaml:"max_preparing"`       // zero means unlimited
+		MaxOrdersPerHour int               `yaml:"max_orders_per_hour"` // zero means unlimited
+		TimeZone         string            `yaml:"timezone"`
+		Hours            map[string]string `yaml:"hours"`         // week day (mon, tue...) to HH:MM-HH:MM or closed, empty means always open
+		Holidays         []string          `yaml:"holidays"`      // YYYY-MM-DD, closed all day
+		AfterHours       string            `yaml:"after_hours"`   // reject or schedule orders placed while closed
+		OverCapacity     string            `yaml:"over_capacity"` // queue or reject
+		Currency         string            `yaml:"currency"`      // ISO 4217 code, USD by default
+		Channels         []ChannelConfig   `yaml:"channels"`
+	}
+
+	// ChannelConfig configures a channel delivering new orders to the
+	// restaurant. No channels means the dashboard only.
+	ChannelConfig struct {
+		Type        string `yaml:"type"`         // dashboard, webhook or printer
+		URL         string `yaml:"url"`          // webhook: POS endpoint
+		Secret      string `yaml:"secret"`       // webhook: key signing the payload
+		SpoolDir    string `yaml:"spool_dir"`    // printer: directory tickets are written to
+		MaxAttempts int    `yaml:"max_attempts"` // zero means the default
+	}
+
+	// LoyaltyConfig models the loyalty points earned on completed orders.
+	LoyaltyConfig struct {
+		PointsPerDollar float32       `yaml:"points_per_dollar"`
+		PointValue      float32       `yaml:"point_value"`   // discount in dollars per redeemed point
+		PointsExpiry    time.Duration `yaml:"points_expiry"` // time after which earned points expire
+	}
+
+	// AuthConfig models the users allowed to log in to the webserver and
+	// the token the workers authenticate with.
+	AuthConfig struct {
+		SessionTTL   time.Duration `yaml:"session_ttl"`
+		ServiceToken string        `yaml:"service_token"`
+		Users        []UserConfig  `yaml:"users"`
+	}
+
+	// WebserverConfig models the listen address and timeouts of the eats
+	// webserver. Zero timeouts mean no timeout.
+	WebserverConfig struct {
+		Addr            string        `yaml:"addr"` // defaults to :8090
+		ReadTimeout     time.Duration `yaml:"read_timeout"`
+		WriteTimeout    time.Duration `yaml:"write_timeout"` // also ends the event streams, which then reconnect
+		IdleTimeout     time.Duration `yaml:"idle_timeout"`
+		ShutdownTimeout time.Duration `yaml:"shutdown_timeout"` // time given to the requests in flight on shutdown
+		AssetsDir       string        `yaml:"assets_dir"`       // serves the assets from disk instead of the binary
+		AssetsMaxAge    time.Duration `yaml:"assets_max_age"`   // time browsers cache the assets, zero revalidates
+	}
+
+	// AdminConfig models the addresses of the admin HTTP servers of the
+	// workers, serving health checks, pprof and build info. An empty
+	// address disables the admin server.
+	AdminConfig struct {
+		EatsWorker string `yaml:"eats_worker"`
+		CronWorker string `yaml:"cron_worker"`
+	}
+
+	// UserConfig models a webserver user. Password is the bcrypt hash
+	// of the password.
+	UserConfig struct {
+		Name     string `yaml:"name"`
+		Role     string `yaml:"role"`
+		Password string `yaml:"password"`
 	}
 )
 
 var domainCreated bool
 
+// URL returns the URL the workers send their requests to the webserver at,
+// on localhost when the webserver listens on all the interfaces.
+func (c WebserverConfig) URL() string {
+	addr := c.Addr
+	if len(addr) == 0 {
+		addr = DefaultWebserverAddr
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "http://" + addr
+	}
+	if ip := net.ParseIP(host); len(host) == 0 || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
+
 func NewRuntime() *Runtime {
 	c := &Runtime{}
 	c.doInit()
@@ -125,4 +232,20 @@ func (h *Runtime) StartWorkers(domainName, groupName string, options cadence.Wor
 		h.Logger.Error("Failed to start workers.", zap.Error(err))
 		panic("Failed to start workers")
 	}
+
+	h.workersLock.Lock()
+	defer h.workersLock.Unlock()
+	h.workers = append(h.workers, &WorkerStatus{
+		TaskList:  groupName,
+		Workflows: !options.DisableWorkflowWorker,
+		Activity:  !options.DisableActivityWorker,
+		StartedAt: time.Now(),
+	})
+}
+
+// Workers returns the workers started by StartWorkers.
+func (h *Runtime) Workers() []*WorkerStatus {
+	h.workersLock.Lock()
+	defer h.workersLock.Unlock()
+	return append([]*WorkerStatus(nil), h.workers...)
 }
@@ -0,0 +1,72 @@
+package message
+
+import (
+	"time"
+)
+
+type (
+	// ShiftEventType is the type of the events signalled to a shift workflow.
+	ShiftEventType string
+
+	// ShiftEvent models a change to a courier shift.
+	ShiftEvent struct {
+		Type    ShiftEventType
+		OrderID string
+	}
+
+	// ShiftStart models the input of the shift workflow of a courier
+	// going online, the initial state of the shift.
+	ShiftStart struct {
+		CourierID string
+		Capacity  int
+	}
+
+	// Payout models the settlement of all the unpaid earnings entries of
+	// a courier, as recorded in the courier earnings ledger.
+	Payout struct {
+		ID        string
+		CourierID string
+		Amount    float32
+		EntryIDs  []string
+		SettledAt time.Time
+	}
+)
+
+const (
+	// ShiftWorkflowName is the name of the courier shift workflow.
+	ShiftWorkflowName = workflowPackage + "courier.ShiftWorkflow"
+
+	// ShiftWorkflowTimeout is the maximum length of a single shift workflow run.
+	ShiftWorkflowTimeout = 24 * time.Hour
+
+	// ShiftSignalName is the name of the signal used to deliver shift events.
+	ShiftSignalName = "SHIFT_EVENT"
+
+	// DeliveryAttemptFailedReason is the reason of the error that completes
+	// DeliverOrderActivity when the courier reports a failed delivery. The
+	// error details hold the failure reason.
+	DeliveryAttemptFailedReason = "DELIVERY_ATTEMPT_FAILED"
+
+	// ReturnLeg is the leg of a failed delivery taking the order back to
+	// the restaurant, which is paid on top of the delivery leg.
+	ReturnLeg = "return"
+)
+
+// Values representing shift events.
+const (
+	SEBreak        ShiftEventType = "BREAK"
+	SEResume       ShiftEventType = "RESUME"
+	SEOffline      ShiftEventType = "OFFLINE"
+	SEJobAssigned  ShiftEventType = "JOB_ASSIGNED"
+	SEJobCompleted ShiftEventType = "JOB_COMPLETED"
+	SEJobReleased  ShiftEventType = "JOB_RELEASED"
+)
+
+// Values representing the availability of a courier.
+const (
+	CSAvailable = "AVAILABLE"
+	CSBusy      = "BUSY"
+	CSOnBreak   = "ON_BREAK"
+	CSEnding    = "ENDING"
+	CSOffline   = "OFFLINE"
+)
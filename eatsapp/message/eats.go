@@ -0,0 +1,33 @@
+package message
+
+import (
+	"time"
+)
+
+type (
+	// ETAUpdate models a revised estimate of when the restaurant
+	// will have the order ready, published when the estimate drifts.
+	ETAUpdate struct {
+		OrderID string
+		ReadyAt time.Time
+	}
+
+	// LoyaltyBalance models the loyalty points balance of a customer.
+	LoyaltyBalance struct {
+		CustomerID string
+		Points     int
+		NextExpiry time.Time
+	}
+)
+
+const (
+	// OrderWorkflowName is the name of the eats order workflow.
+	OrderWorkflowName = workflowPackage + "eats.OrderWorkflow"
+
+	// ETASignalName is the signal sent when the restaurant revises the order ETA.
+	ETASignalName = "ETA_UPDATE"
+
+	// OrderCancelledReason is the reason of the error returned
+	// when the customer cancels the order.
+	OrderCancelledReason = "ORDER_CANCELLED"
+)
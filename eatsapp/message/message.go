@@ -0,0 +1,9 @@
+// Package message defines the data exchanged by the workers and the
+// webserver: workflow names, signals, error reasons and the results of
+// the activities. The webserver uses it rather than the worker packages,
+// whose init functions register the workflows and activities.
+package message
+
+// workflowPackage is the package path of the worker workflows, workflows
+// are registered under the package path and name of their function.
+const workflowPackage = "github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/"
@@ -0,0 +1,35 @@
+// Package assets embeds the static files, page templates, message catalogs
+// and default menu of the webserver, so that the binary runs from any
+// directory.
+package assets
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+)
+
+// MenuFile is the path of the default menu in the assets.
+const MenuFile = "data/menu.yaml"
+
+// embedded excludes the images uploaded at runtime, which
+// are served from the disk.
+//
+//go:embed css js images/*.jpg tmpl i18n data
+var embedded embed.FS
+
+// Open returns the embedded assets, or the assets of dir when it is not
+// empty, which lets developers edit the pages without rebuilding.
+func Open(dir string) (fs.FS, error) {
+	if len(dir) == 0 {
+		return embedded, nil
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, &fs.PathError{Op: "open", Path: dir, Err: fs.ErrInvalid}
+	}
+	return os.DirFS(dir), nil
+}
@@ -5,10 +5,17 @@ import (
 	"net/http"
 
 	"github.com/venkat1109/cadence-codelab/common"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/assets"
 	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
 	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/courier"
 	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/eats"
 	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/restaurant"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+	"go.uber.org/zap"
+)
+
+const (
+	courierLedgerFile = "eatsapp/webserver/data/courier-ledger.json"
 )
 
 func main() {
@@ -19,23 +26,117 @@ func main() {
 		panic(err)
 	}
 
-	service.LoadTemplates()
+	assetsFS, err := assets.Open(runtime.Config.Webserver.AssetsDir)
+	if err != nil {
+		panic(err)
+	}
+	service.LoadTemplates(assetsFS, runtime.Config.Restaurant.Currency)
+	service.SetLogger(runtime.Logger)
+
+	backend, err := store.New(runtime.Config.Store)
+	if err != nil {
+		panic(err)
+	}
+	health := common.NewHealth()
+	health.AddCheck("cadence", runtime.CadenceCheck())
+	health.AddCheck("store", func() error { return store.Ping(backend) })
+
+	// pages subscribed to the events are pushed every state change
+	broker := service.NewBroker()
+	observed := store.Observe(backend, broker.StoreChanged)
+
+	restaurantService := restaurant.NewService(workflowClient, assetsFS, assets.MenuFile, observed, runtime.Config.Restaurant)
+
+	couriers := courier.NewService(workflowClient, observed)
+	eatsService := eats.NewService(workflowClient, restaurantService.GetMenu(), restaurantService.Hours(), runtime.Config.Loyalty, observed)
+
+	auth, err := service.NewAuth(runtime.Config.Auth, observed)
+	if err != nil {
+		panic(err)
+	}
+
+	http.Handle("/restaurant", auth.Protect(restaurant.OrdersPolicy, restaurantService))
+	http.Handle("/restaurant-kitchen", auth.Protect(restaurant.KitchenPolicy, restaurant.NewKitchenService(restaurantService)))
+	http.Handle("/restaurant-eta", auth.Protect(restaurant.StaffPolicy, restaurant.NewETAService(restaurantService)))
+	http.Handle("/restaurant-capacity", auth.Protect(restaurant.StaffPolicy, restaurant.NewCapacityService(restaurantService)))
+	http.Handle("/restaurant-menu", auth.Protect(restaurant.StaffPolicy, restaurant.NewMenuService(restaurantService)))
+	http.Handle("/restaurant-stock", auth.Protect(restaurant.StaffPolicy, restaurant.NewStockService(restaurantService)))
+	http.Handle("/restaurant-changes", auth.Protect(restaurant.StaffPolicy, restaurant.NewChangesService(restaurantService)))
+	http.Handle("/restaurant-hours", auth.Protect(restaurant.StaffPolicy, restaurant.NewHoursService(restaurantService)))
+	http.Handle("/restaurant-reports", auth.Protect(restaurant.StaffPolicy, restaurant.NewReportService(restaurantService)))
+	http.Handle("/courier", auth.Protect(courier.JobsPolicy, couriers))
+	http.Handle("/courier-shift", auth.Protect(courier.ShiftPolicy, courier.NewShiftService(couriers)))
+	http.Handle("/courier-earnings", auth.Protect(courier.EarningsPolicy, courier.NewEarningsService(couriers, courierLedgerFile, courier.DefaultFareModel)))
+	http.Handle("/eats-orders", auth.Protect(eats.OrdersPolicy, eatsService))
+	http.Handle("/eats-delivery", auth.Protect(eats.ResponsePolicy, eats.NewContactService(eatsService)))
+	http.Handle("/eats-feedback", auth.Protect(eats.ResponsePolicy, eats.NewFeedbackService(eatsService)))
+	http.Handle("/eats-substitution", auth.Protect(eats.ResponsePolicy, eats.NewSubstitutionService(eatsService)))
+	http.Handle("/eats-changes", auth.Protect(eats.ResponsePolicy, eats.NewChangesService(eatsService)))
+	http.Handle("/eats-customer", auth.Protect(eats.CustomerPolicy, eats.NewCustomerService(eatsService)))
+	http.Handle("/eats-history", auth.Protect(eats.HistoryPolicy, eats.NewHistoryService(eatsService)))
+	http.Handle("/eats-loyalty", auth.Protect(eats.LoyaltyPolicy, eats.NewLoyaltyService(eatsService)))
+	http.Handle("/eats-price", auth.Protect(eats.PricePolicy, eats.NewPriceService(eatsService)))
 
-	restaurant := restaurant.NewService(workflowClient, "eatsapp/webserver/assets/data/menu.yaml")
+	api := service.NewAPI("/api/v1", "Cadence Bistro API", "1.0.0")
+	restaurantService.RegisterAPI(api)
+	couriers.RegisterAPI(api)
+	eatsService.RegisterAPI(api)
+	http.Handle("/api/v1/", auth.Authenticate(api))
 
-	http.Handle("/restaurant", restaurant)
-	http.Handle("/courier", courier.NewService(workflowClient))
-	http.Handle("/eats-orders", eats.NewService(workflowClient, restaurant.GetMenu()))
-	http.Handle("/", http.FileServer(http.Dir(".")))
+	broker.Authorize(service.OrderTopicPrefix, eatsService.AuthorizeTopic)
+	broker.Authorize("restaurant", service.RoleTopic(service.RoleStaff))
+	broker.Authorize("courier", service.RoleTopic(service.RoleCourier))
+	broker.Watch(service.OrderTopicPrefix, eatsService.WatchHistory, "run_id")
+	broker.OrderTables(eats.OrderTables...)
+	http.Handle("/events", auth.Protect(service.Policy{"GET": service.AllRoles}, broker))
 
-	http.HandleFunc("/eats-menu", func(w http.ResponseWriter, r *http.Request) {
-		service.ViewHandler(w, r, restaurant.GetMenu())
+	// health checks are public for the orchestrator, debug endpoints are for admins
+	http.HandleFunc("/healthz", health.ServeLiveness)
+	http.HandleFunc("/readyz", health.ServeReadiness)
+	debug := http.NewServeMux()
+	debug.HandleFunc("/buildinfo", common.ServeBuildInfo)
+	common.HandlePprof(debug)
+	http.Handle("/buildinfo", auth.Protect(service.Policy{}, debug))
+	http.Handle("/debug/pprof/", auth.Protect(service.Policy{}, debug))
+
+	http.HandleFunc("/login", auth.ServeLogin)
+	http.HandleFunc("/logout", auth.ServeLogout)
+	// only the stylesheets, scripts and images are served, assets edited
+	// on disk during development are revalidated on every use
+	maxAge := runtime.Config.Webserver.AssetsMaxAge
+	if len(runtime.Config.Webserver.AssetsDir) > 0 {
+		maxAge = 0
+	}
+	http.Handle(service.AssetsPrefix, service.NewStaticHandler(assetsFS, restaurant.ImagesDir, maxAge))
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			service.NotFound(w, r)
+			return
+		}
+		http.Redirect(w, r, "/bistro", http.StatusFound)
 	})
+
+	http.Handle("/eats-menu", auth.Protect(eats.MenuPolicy, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		service.ViewHandler(w, r, restaurantService.MenuPage())
+	})))
 	// setup & start server
 	http.HandleFunc("/bistro", func(w http.ResponseWriter, r *http.Request) {
 		service.ViewHandler(w, r, nil)
 	})
 
+	handler := service.Chain(http.DefaultServeMux,
+		service.RequestID,
+		service.AccessLog(runtime.Logger),
+		service.Metrics(runtime.Scope.SubScope("webserver"), http.DefaultServeMux),
+		service.Recover(runtime.Logger),
+		service.Gzip,
+	)
+	server := service.NewServer(runtime.Config.Webserver, handler)
+	server.RegisterOnShutdown(broker.Close)
+
 	fmt.Println("Starting Webserver")
-	http.ListenAndServe(":8090", nil)
+	if err := service.ListenAndServe(server, runtime.Config.Webserver.ShutdownTimeout, runtime.Logger); err != nil {
+		runtime.Logger.Fatal("Webserver failed", zap.Error(err))
+	}
+	fmt.Println("Webserver stopped")
 }
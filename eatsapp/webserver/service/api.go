@@ -0,0 +1,359 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+)
+
+type (
+	// API routes the requests sent to the versioned JSON API and
+	// describes its routes in an OpenAPI document.
+	API struct {
+		prefix  string
+		title   string
+		version string
+		routes  []*Route
+	}
+
+	// Route describes an API route. Path is relative to the API prefix,
+	// its {name} segments are passed to the handler as path params.
+	Route struct {
+		Method   string
+		Path     string
+		Summary  string
+		Query    []string    // names of the optional query params
+		Body     interface{} // sample of the request body, nil if none
+		Response interface{} // sample of the response body
+		Status   int         // status of successful responses, 200 if zero
+		Roles    []string    // roles allowed to use the route, nil for any user
+		Handler  APIHandlerFunc
+	}
+
+	// APIHandlerFunc handles an API request, the result is written as JSON.
+	APIHandlerFunc func(r *http.Request, params map[string]string) (interface{}, error)
+
+	// APIError is the error returned by the API, its code is derived
+	// from the HTTP status, e.g. "not_found" or "unprocessable_entity".
+	APIError struct {
+		Status  int    `json:"-"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+
+	// errorBody is the body of every API error response.
+	errorBody struct {
+		Error *APIError `json:"error"`
+	}
+
+	// responseRecorder captures the response of a handler called by the API.
+	responseRecorder struct {
+		header http.Header
+		status int
+		body   bytes.Buffer
+	}
+)
+
+const jsonContentType = "application/json"
+
+// NewAPI returns a new API served under the prefix, e.g. "/api/v1".
+func NewAPI(prefix string, title string, version string) *API {
+	api := &API{
+		prefix:  strings.TrimSuffix(prefix, "/"),
+		title:   title,
+		version: version,
+	}
+	api.Handle(&Route{
+		Method:   "GET",
+		Path:     "/openapi.json",
+		Summary:  "Returns the OpenAPI document describing the API",
+		Response: map[string]interface{}{},
+		Handler: func(r *http.Request, params map[string]string) (interface{}, error) {
+			return api.OpenAPI(), nil
+		},
+	})
+	return api
+}
+
+// Handle adds the route to the API.
+func (a *API) Handle(route *Route) {
+	a.routes = append(a.routes, route)
+}
+
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !AcceptsJSON(r) {
+		WriteError(w, NewAPIError(http.StatusNotAcceptable, "The API only serves "+jsonContentType))
+		return
+	}
+
+	route, params, allowed := a.match(r)
+	if route == nil {
+		if len(allowed) > 0 {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			WriteError(w, NewAPIError(http.StatusMethodNotAllowed, "Method not allowed: "+r.Method))
+			return
+		}
+		WriteError(w, NewAPIError(http.StatusNotFound, "Unknown API path: "+r.URL.Path))
+		return
+	}
+
+	if user := CurrentUser(r); user == nil {
+		WriteError(w, NewAPIError(http.StatusUnauthorized, "Authentication required"))
+		return
+	} else if route.Roles != nil && !HasRole(user, route.Roles) {
+		WriteError(w, NewAPIError(http.StatusForbidden, "Forbidden for role "+user.Role))
+		return
+	}
+	if !VerifyCSRF(r) {
+		WriteError(w, NewAPIError(http.StatusForbidden, errInvalidCSRF.Error()))
+		return
+	}
+
+	result, err := route.Handler(r, params)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	WriteJSON(w, status, result)
+}
+
+// match returns the route and path params matching the request, or the
+// methods allowed on the path if no route matches the request method.
+func (a *API) match(r *http.Request) (*Route, map[string]string, []string) {
+	if !strings.HasPrefix(r.URL.Path, a.prefix+"/") {
+		return nil, nil, nil
+	}
+	segments := splitPath(strings.TrimPrefix(r.URL.Path, a.prefix))
+
+	var allowed []string
+	for _, route := range a.routes {
+		params, ok := matchPath(splitPath(route.Path), segments)
+		if !ok {
+			continue
+		}
+		if route.Method == r.Method {
+			return route, params, nil
+		}
+		allowed = append(allowed, route.Method)
+	}
+	return nil, nil, allowed
+}
+
+func splitPath(p string) []string {
+	return strings.Split(strings.Trim(p, "/"), "/")
+}
+
+func matchPath(pattern []string, segments []string) (map[string]string, bool) {
+	if len(pattern) != len(segments) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, p := range pattern {
+		if strings.HasPrefix(p, "{") && strings.HasSuffix(p, "}") {
+			if len(segments[i]) == 0 {
+				return nil, false
+			}
+			params[p[1:len(p)-1]] = segments[i]
+			continue
+		}
+		if p != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// AcceptsJSON returns true if the Accept header of the request allows
+// a JSON response. Requests without an Accept header accept anything.
+func AcceptsJSON(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	if len(accept) == 0 {
+		return true
+	}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || params["q"] == "0" {
+			continue
+		}
+		switch mediaType {
+		case jsonContentType, "application/*", "*/*":
+			return true
+		}
+	}
+	return false
+}
+
+// NewAPIError returns an API error with the code derived from the status.
+func NewAPIError(status int, message string) *APIError {
+	code := strings.ToLower(strings.Replace(http.StatusText(status), " ", "_", -1))
+	if len(code) == 0 {
+		code = "error"
+	}
+	return &APIError{
+		Status:  status,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// WriteJSON writes the value as a JSON response with the status.
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", jsonContentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// WriteError writes the error as a JSON error response. Errors other than
+// APIError are reported as not found if they are store.ErrNotFound, and
+// as internal server errors otherwise.
+func WriteError(w http.ResponseWriter, err error) {
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		if err == store.ErrNotFound {
+			apiErr = NewAPIError(http.StatusNotFound, err.Error())
+		} else {
+			apiErr = NewAPIError(http.StatusInternalServerError, err.Error())
+		}
+	}
+	WriteJSON(w, apiErr.Status, &errorBody{Error: apiErr})
+}
+
+// Delegate calls a handler of the HTML services with the query params
+// replaced, so that the API shares their validation and side effects.
+// Responses with an error status are returned as an APIError, otherwise
+// the recorded response headers are returned.
+func Delegate(handler http.Handler, r *http.Request, query url.Values) (http.Header, error) {
+	req := new(http.Request)
+	*req = *r
+	u := *r.URL
+	u.RawQuery = query.Encode()
+	req.URL = &u
+
+	rec := &responseRecorder{header: make(http.Header), status: http.StatusOK}
+	handler.ServeHTTP(rec, req)
+	if rec.status >= http.StatusBadRequest {
+		return nil, NewAPIError(rec.status, strings.TrimSpace(rec.body.String()))
+	}
+	return rec.header, nil
+}
+
+func (rec *responseRecorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *responseRecorder) Write(data []byte) (int, error) {
+	return rec.body.Write(data)
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	rec.status = status
+}
+
+// OpenAPI returns the OpenAPI 3 document describing the API routes,
+// with the schemas of the request and response bodies derived from
+// the samples registered with the routes.
+func (a *API) OpenAPI() map[string]interface{} {
+	schemas := make(map[string]interface{})
+	paths := make(map[string]map[string]interface{})
+
+	for _, route := range a.routes {
+		p := path.Join(a.prefix, route.Path)
+		if paths[p] == nil {
+			paths[p] = make(map[string]interface{})
+		}
+
+		var params []interface{}
+		for _, segment := range splitPath(route.Path) {
+			if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+				params = append(params, openAPIParam(segment[1:len(segment)-1], "path", true))
+			}
+		}
+		for _, name := range route.Query {
+			params = append(params, openAPIParam(name, "query", false))
+		}
+
+		status := route.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		op := map[string]interface{}{
+			"summary":     route.Summary,
+			"operationId": operationID(route),
+			"responses": map[string]interface{}{
+				fmt.Sprint(status): openAPIResponse("Success", schemaOf(route.Response, schemas)),
+				"default":          openAPIResponse("Error", schemaOf(errorBody{}, schemas)),
+			},
+		}
+		if len(params) > 0 {
+			op["parameters"] = params
+		}
+		if route.Body != nil {
+			op["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					jsonContentType: map[string]interface{}{"schema": schemaOf(route.Body, schemas)},
+				},
+			}
+		}
+		paths[p][strings.ToLower(route.Method)] = op
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.0",
+		"info": map[string]interface{}{
+			"title":   a.title,
+			"version": a.version,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+		},
+	}
+}
+
+func openAPIParam(name string, in string, required bool) map[string]interface{} {
+	return map[string]interface{}{
+		"name":     name,
+		"in":       in,
+		"required": required,
+		"schema":   map[string]interface{}{"type": "string"},
+	}
+}
+
+func openAPIResponse(description string, schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			jsonContentType: map[string]interface{}{"schema": schema},
+		},
+	}
+}
+
+// operationID derives a unique operation ID from the route method and path,
+// e.g. "getOrdersById" for GET /orders/{id}.
+func operationID(route *Route) string {
+	id := strings.ToLower(route.Method)
+	for _, segment := range splitPath(route.Path) {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			id += "By" + strings.Title(segment[1:len(segment)-1])
+			continue
+		}
+		id += strings.Title(strings.TrimSuffix(segment, path.Ext(segment)))
+	}
+	return id
+}
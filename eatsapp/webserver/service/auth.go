@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	config "github.com/venkat1109/cadence-codelab/common"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type (
+	// Auth authenticates the webserver users with session cookies, and
+	// the workers with the service token, and authorizes their requests.
+	Auth struct {
+		users        map[string]config.UserConfig
+		serviceToken string
+		sessionTTL   time.Duration
+		store        store.Store
+	}
+
+	// User models an authenticated user.
+	User struct {
+		Name string
+		Role string
+	}
+
+	// Session models the session of a logged in user.
+	Session struct {
+		ID        string
+		User      User
+		CSRFToken string
+		ExpiresAt time.Time
+	}
+
+	// Policy maps "METHOD" or "METHOD action" to the roles allowed to
+	// send such requests, "action" being the value of the action param.
+	// Admins and the workers are allowed to send any request.
+	Policy map[string][]string
+
+	// LoginPage models the data to be displayed on the login page.
+	LoginPage struct {
+		Next  string
+		Error string
+	}
+
+	// identity is stored in the request context by Authenticate.
+	identity struct {
+		user    *User
+		session *Session
+	}
+
+	contextKey string
+)
+
+// Values representing user roles.
+const (
+	RoleCustomer = "customer"
+	RoleStaff    = "restaurant"
+	RoleCourier  = "courier"
+	RoleAdmin    = "admin"
+	// RoleService is the role of the workers, authenticated by the service token.
+	RoleService = "service"
+)
+
+const (
+	sessionCookie     = "bistro_session"
+	csrfCookie        = "bistro_csrf" // readable by the page scripts
+	csrfHeader        = "X-CSRF-Token"
+	csrfFormField     = "csrf_token"
+	sessionsTable     = "auth-sessions"
+	defaultSessionTTL = time.Hour * 12
+
+	identityKey contextKey = "identity"
+)
+
+// AllRoles lists the roles of the users who log in.
+var AllRoles = []string{RoleCustomer, RoleStaff, RoleCourier, RoleAdmin}
+
+// dummyHash is the bcrypt hash of "dummy", see verify.
+var dummyHash = []byte("$2a$10$AG12ktYq4l3283aDWY2C5utreaOF663hdAfKmRbEKmta4a.T6KMlC")
+
+var (
+	errInvalidLogin = errors.New("invalid user name or password")
+	errInvalidCSRF  = errors.New("invalid or missing CSRF token")
+)
+
+// homePages are the pages users are sent to after logging in.
+var homePages = map[string]string{
+	RoleCustomer: "/eats-menu",
+	RoleStaff:    "/restaurant",
+	RoleCourier:  "/courier",
+	RoleAdmin:    "/bistro",
+}
+
+// NewAuth returns the Auth of the users listed in the configuration.
+func NewAuth(cfg config.AuthConfig, s store.Store) (*Auth, error) {
+	a := &Auth{
+		users:        make(map[string]config.UserConfig),
+		serviceToken: cfg.ServiceToken,
+		sessionTTL:   cfg.SessionTTL,
+		store:        s,
+	}
+	if a.sessionTTL <= 0 {
+		a.sessionTTL = defaultSessionTTL
+	}
+	for _, user := range cfg.Users {
+		if _, ok := homePages[user.Role]; !ok {
+			return nil, fmt.Errorf("invalid role %q of user %q", user.Role, user.Name)
+		}
+		if _, err := bcrypt.Cost([]byte(user.Password)); err != nil {
+			return nil, fmt.Errorf("invalid password of user %q, expected a bcrypt hash: %v", user.Name, err)
+		}
+		a.users[user.Name] = user
+	}
+	return a, nil
+}
+
+// Authenticate resolves the user sending the request, if any, before
+// calling h. CurrentUser returns the user from the request context.
+func (a *Auth) Authenticate(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := a.identify(r); id != nil {
+			r = r.WithContext(context.WithValue(r.Context(), identityKey, id))
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// Protect only lets the requests allowed by the policy through to h.
+// Anonymous page requests are redirected to the login page.
+func (a *Auth) Protect(policy Policy, h http.Handler) http.Handler {
+	return a.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := CurrentUser(r)
+		if user == nil {
+			if r.Method == "GET" && !strings.Contains(r.Header.Get("Accept"), jsonContentType) {
+				http.Redirect(w, r, "/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusFound)
+				return
+			}
+			http.Error(w, "Authentication required", http.StatusUnauthorized)
+			return
+		}
+		if !policy.Allows(user, r) {
+			http.Error(w, "Forbidden for role "+user.Role, http.StatusForbidden)
+			return
+		}
+		if !VerifyCSRF(r) {
+			http.Error(w, errInvalidCSRF.Error(), http.StatusForbidden)
+			return
+		}
+		h.ServeHTTP(w, r)
+	}))
+}
+
+// Allows returns true if the user may send the request.
+func (p Policy) Allows(user *User, r *http.Request) bool {
+	if user.Role == RoleAdmin || user.Role == RoleService {
+		return true
+	}
+	roles, ok := p[r.Method+" "+r.URL.Query().Get("action")]
+	if !ok {
+		roles = p[r.Method]
+	}
+	return HasRole(user, roles)
+}
+
+// HasRole returns true if the user has one of the roles, admins and
+// the workers have every role.
+func HasRole(user *User, roles []string) bool {
+	if user.Role == RoleAdmin || user.Role == RoleService {
+		return true
+	}
+	for _, role := range roles {
+		if role == user.Role {
+			return true
+		}
+	}
+	return false
+}
+
+// CurrentUser returns the user sending the request, nil if anonymous.
+func CurrentUser(r *http.Request) *User {
+	if id, ok := r.Context().Value(identityKey).(*identity); ok {
+		return id.user
+	}
+	return nil
+}
+
+// VerifyCSRF returns false if the request changes state on behalf of a
+// logged in user without the CSRF token of the session. Requests sent
+// with the service token carry no cookies and are not subject to CSRF.
+func VerifyCSRF(r *http.Request) bool {
+	id, ok := r.Context().Value(identityKey).(*identity)
+	if !ok || id.session == nil {
+		return true
+	}
+	switch r.Method {
+	case "GET", "HEAD", "OPTIONS":
+		return true
+	}
+	token := r.Header.Get(csrfHeader)
+	if len(token) == 0 {
+		token = r.FormValue(csrfFormField)
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(id.session.CSRFToken)) == 1
+}
+
+// identify returns the identity of the service token or session cookie.
+func (a *Auth) identify(r *http.Request) *identity {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		token := strings.TrimPrefix(auth, "Bearer ")
+		if len(a.serviceToken) > 0 && subtle.ConstantTimeCompare([]byte(token), []byte(a.serviceToken)) == 1 {
+			return &identity{user: &User{Name: RoleService, Role: RoleService}}
+		}
+		return nil
+	}
+
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return nil
+	}
+	var session Session
+	if err := store.GetJSON(a.store, sessionsTable, cookie.Value, &session); err != nil {
+		return nil
+	}
+	if time.Now().After(session.ExpiresAt) {
+		a.store.Delete(sessionsTable, session.ID)
+		return nil
+	}
+	return &identity{user: &session.User, session: &session}
+}
+
+// ServeLogin shows the login form on GET and logs the user in on POST.
+// JSON clients receive the CSRF token of the session in the response.
+func (a *Auth) ServeLogin(w http.ResponseWriter, r *http.Request) {
+	page := LoginPage{Next: r.FormValue("next")}
+	switch r.Method {
+	case "GET":
+		ViewHandler(w, r, page)
+		return
+	case "POST":
+	default:
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+
+	wantsJSON := strings.Contains(r.Header.Get("Accept"), jsonContentType)
+	user, err := a.verify(r.FormValue("name"), r.FormValue("password"))
+	if err != nil && wantsJSON {
+		WriteError(w, NewAPIError(http.StatusUnauthorized, err.Error()))
+		return
+	}
+	if err != nil {
+		page.Error = err.Error()
+		RenderView(w, r, http.StatusUnauthorized, "login", page)
+		return
+	}
+
+	session, err := a.newSession(user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	setCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    session.ID,
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+	})
+	setCookie(w, &http.Cookie{
+		Name:    csrfCookie,
+		Value:   session.CSRFToken,
+		Expires: session.ExpiresAt,
+	})
+
+	if wantsJSON {
+		WriteJSON(w, http.StatusOK, map[string]string{
+			"Name":      user.Name,
+			"Role":      user.Role,
+			"CSRFToken": session.CSRFToken,
+		})
+		return
+	}
+	http.Redirect(w, r, nextPage(page.Next, user), http.StatusFound)
+}
+
+// ServeLogout ends the session of the user.
+func (a *Auth) ServeLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		a.store.Delete(sessionsTable, cookie.Value)
+	}
+	for _, name := range []string{sessionCookie, csrfCookie} {
+		setCookie(w, &http.Cookie{Name: name, MaxAge: -1})
+	}
+	http.Redirect(w, r, "/login", http.StatusFound)
+}
+
+// verify returns the user if the password matches the configured hash.
+// Unknown users are checked against a dummy hash, so that they take as
+// long to reject as wrong passwords.
+func (a *Auth) verify(name string, password string) (*User, error) {
+	cfg, ok := a.users[name]
+	hash := []byte(cfg.Password)
+	if !ok {
+		hash = dummyHash
+	}
+	if err := bcrypt.CompareHashAndPassword(hash, []byte(password)); err != nil || !ok {
+		return nil, errInvalidLogin
+	}
+	return &User{Name: cfg.Name, Role: cfg.Role}, nil
+}
+
+// setCookie sets the cookie on the whole site. Browsers only send the
+// cookies over https, or to localhost, and not on requests from other sites.
+func setCookie(w http.ResponseWriter, cookie *http.Cookie) {
+	cookie.Path = "/"
+	cookie.Secure = true
+	cookie.SameSite = http.SameSiteLaxMode
+	http.SetCookie(w, cookie)
+}
+
+func (a *Auth) newSession(user *User) (*Session, error) {
+	id, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	csrf, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	session := &Session{
+		ID:        id,
+		User:      *user,
+		CSRFToken: csrf,
+		ExpiresAt: time.Now().Add(a.sessionTTL),
+	}
+	if err := store.PutJSON(a.store, sessionsTable, session.ID, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// nextPage returns the local page to redirect to after login, or the home
+// page of the user role. Absolute and protocol relative URLs are ignored.
+func nextPage(next string, user *User) string {
+	if strings.HasPrefix(next, "/") && !strings.HasPrefix(next, "//") && !strings.HasPrefix(next, "/\\") {
+		return next
+	}
+	return homePages[user.Role]
+}
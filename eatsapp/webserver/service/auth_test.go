@@ -0,0 +1,137 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	config "github.com/venkat1109/cadence-codelab/common"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func testAuth(t *testing.T) *Auth {
+	var users []config.UserConfig
+	for name, role := range map[string]string{"joe": RoleCustomer, "bistro": RoleStaff} {
+		hash, err := bcrypt.GenerateFromPassword([]byte(name+"-password"), bcrypt.MinCost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		users = append(users, config.UserConfig{Name: name, Role: role, Password: string(hash)})
+	}
+	auth, err := NewAuth(config.AuthConfig{ServiceToken: "worker-token", Users: users}, store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return auth
+}
+
+// login logs the user in and returns the session cookies, nil if the login failed.
+func login(auth *Auth, name string, password string) []*http.Cookie {
+	form := url.Values{"name": {name}, "password": {password}}
+	r := httptest.NewRequest("POST", "/login", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("Accept", jsonContentType)
+	w := httptest.NewRecorder()
+	auth.ServeLogin(w, r)
+	if w.Code != http.StatusOK {
+		return nil
+	}
+	return w.Result().Cookies()
+}
+
+func TestNewAuthRejectsUnhashedPasswords(t *testing.T) {
+	cfg := config.AuthConfig{Users: []config.UserConfig{{Name: "joe", Role: RoleCustomer, Password: "joe"}}}
+	if _, err := NewAuth(cfg, store.NewMemoryStore()); err == nil {
+		t.Error("NewAuth() accepted a plain text password")
+	}
+}
+
+func TestLogin(t *testing.T) {
+	auth := testAuth(t)
+	tests := []struct {
+		name     string
+		user     string
+		password string
+		ok       bool
+	}{
+		{"valid password", "joe", "joe-password", true},
+		{"wrong password", "joe", "bistro-password", false},
+		{"unknown user", "jane", "joe-password", false},
+		{"empty password", "joe", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cookies := login(auth, tt.user, tt.password)
+			if ok := cookies != nil; ok != tt.ok {
+				t.Fatalf("login succeeded = %v, want %v", ok, tt.ok)
+			}
+			for _, cookie := range cookies {
+				if !cookie.Secure || cookie.SameSite != http.SameSiteLaxMode || cookie.Path != "/" {
+					t.Errorf("cookie %s is not secure, same site and site wide: %+v", cookie.Name, cookie)
+				}
+				if cookie.Name == sessionCookie && !cookie.HttpOnly {
+					t.Errorf("session cookie is readable by the page scripts")
+				}
+			}
+		})
+	}
+}
+
+func TestProtect(t *testing.T) {
+	auth := testAuth(t)
+	policy := Policy{
+		"GET":          {RoleCustomer, RoleStaff},
+		"PATCH":        {RoleStaff},
+		"PATCH cancel": {RoleCustomer, RoleStaff},
+	}
+	handler := auth.Protect(policy, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(CurrentUser(r).Name))
+	}))
+
+	sessions := make(map[string][]*http.Cookie)
+	for _, name := range []string{"joe", "bistro"} {
+		if sessions[name] = login(auth, name, name+"-password"); sessions[name] == nil {
+			t.Fatalf("login of %s failed", name)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		query  string
+		user   string // session of the user, "worker" sends the service token
+		csrf   bool
+		status int
+	}{
+		{"anonymous page", "GET", "", "", false, http.StatusFound},
+		{"anonymous update", "PATCH", "", "", false, http.StatusUnauthorized},
+		{"customer page", "GET", "", "joe", false, http.StatusOK},
+		{"customer update", "PATCH", "?action=accept", "joe", true, http.StatusForbidden},
+		{"customer action", "PATCH", "?action=cancel", "joe", true, http.StatusOK},
+		{"staff update", "PATCH", "?action=accept", "bistro", true, http.StatusOK},
+		{"missing csrf token", "PATCH", "?action=accept", "bistro", false, http.StatusForbidden},
+		{"service token", "PATCH", "?action=accept", "worker", false, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/restaurant"+tt.query, nil)
+			if tt.user == "worker" {
+				r.Header.Set("Authorization", "Bearer worker-token")
+			}
+			for _, cookie := range sessions[tt.user] {
+				r.AddCookie(cookie)
+				if tt.csrf && cookie.Name == csrfCookie {
+					r.Header.Set(csrfHeader, cookie.Value)
+				}
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+		})
+	}
+}
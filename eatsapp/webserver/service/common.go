@@ -4,8 +4,10 @@ import (
 	"errors"
 	"gopkg.in/yaml.v2"
 	"html/template"
-	"io/ioutil"
+	"io/fs"
 	"net/http"
+	"sync"
+	"time"
 )
 
 type (
@@ -16,51 +18,106 @@ type (
 		Description string
 		Image       string
 		Price       float32
+		Station     string
+		PrepTime    time.Duration `yaml:"prep_time"`
+		Available   bool
+		TrackStock  bool `yaml:"track_stock"`
+		Stock       int
+		Version     int `yaml:"-"`
 	}
 
-	// Menu models a restaurant menu.
+	// Menu models a restaurant menu. Items are never modified in place,
+	// changes replace the item so that readers always see a consistent copy.
 	Menu struct {
-		Items []*Item
+		sync.RWMutex `json:"-" yaml:"-"`
+		Items        []*Item
+	}
+
+	// OrderPrice models the pricing step of a new order, including
+	// the discount paid with loyalty points. Total is the amount
+	// charged, repriced when the restaurant modifies the order. The
+	// tip is charged on top of the Total and shared with the courier.
+	OrderPrice struct {
+		OrderID        string
+		CustomerID     string
+		Subtotal       float32
+		PointsRedeemed int
+		Discount       float32
+		Total          float32
+		Tip            float32
 	}
 )
 
 const (
-	// TemplatesGlob stores the value used to glob for templates.
-	TemplatesGlob = "eatsapp/webserver/assets/tmpl/*"
+	// TemplatesGlob stores the value used to glob for templates in the assets.
+	TemplatesGlob = "tmpl/*"
+
+	// OrderPricesTable is the table of the prices of the eats orders.
+	OrderPricesTable = "eats-order-prices"
 )
 
-// Templates stores the pre-processed templates.
-var Templates *template.Template
+// Values representing the kitchen stations that prepare menu items.
+const (
+	StationGrill  = "grill"
+	StationBakery = "bakery"
+	StationCold   = "cold"
+)
 
-func LoadTemplates() {
-	Templates = template.Must(template.ParseGlob(TemplatesGlob))
-}
+// DefaultPrepTime is the prep time of items without a prep time in the menu.
+const DefaultPrepTime = time.Minute * 5
 
-// ViewHandler renders a http response using a template based on "page" param or request path.
-func ViewHandler(w http.ResponseWriter, r *http.Request, data interface{}) error {
-	page := r.URL.Query().Get("page")
-	if len(page) == 0 {
-		page = r.URL.Path[1:]
-	}
+// errPageNotFound is returned by ViewHandler for the pages the route
+// does not render.
+var errPageNotFound = errors.New("page not found")
+
+// Stations lists the kitchen stations in the order they are displayed.
+var Stations = []string{StationGrill, StationBakery, StationCold}
+
+// Templates stores the pre-processed templates of the default locale.
+var Templates *template.Template
 
-	err := Templates.ExecuteTemplate(w, page, data)
+// LoadTemplates parses the templates of the assets once for the default
+// locale and once for every message catalog of the assets, with the
+// template funcs translating the messages and formatting the amounts in
+// the currency.
+func LoadTemplates(assets fs.FS, currency string) {
+	catalogs, err := loadCatalogs(assets)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return err
+		panic(err)
 	}
+	catalogs[DefaultLocale] = nil
 
-	return nil
+	localizedTemplates = make(map[string]*template.Template, len(catalogs))
+	for locale, catalog := range catalogs {
+		funcs := NewLocalizer(locale, catalog, currency).FuncMap()
+		localizedTemplates[locale] = template.Must(template.New("").Funcs(funcs).ParseFS(assets, TemplatesGlob))
+	}
+	Templates = localizedTemplates[DefaultLocale]
+}
+
+// ViewHandler renders a http response using the page of the request path,
+// or the page of the "page" param if the route lists it.
+func ViewHandler(w http.ResponseWriter, r *http.Request, data interface{}) error {
+	page, ok := viewOf(r)
+	if !ok {
+		NotFound(w, r)
+		return errPageNotFound
+	}
+	return RenderView(w, r, http.StatusOK, page, data)
 }
 
 // NewMenu returns a new Menu object whose
 // contents are loaded from the specified
-// file path
-func NewMenu(file string) (*Menu, error) {
-	return loadMenu(file)
+// file of the assets
+func NewMenu(assets fs.FS, file string) (*Menu, error) {
+	return loadMenu(assets, file)
 }
 
 // GetItemByID returns the item matching the ID value passed as a param.
 func (m *Menu) GetItemByID(id string) (*Item, error) {
+	m.RLock()
+	defer m.RUnlock()
+
 	for _, v := range m.Items {
 		if v.ID == id {
 			return v, nil
@@ -70,9 +127,26 @@ func (m *Menu) GetItemByID(id string) (*Item, error) {
 	return nil, errors.New("Invalid menu item: " + id)
 }
 
+// KitchenStation returns the station that prepares the item, items
+// without a station in the menu are prepared at the cold station.
+func (i *Item) KitchenStation() string {
+	if len(i.Station) == 0 {
+		return StationCold
+	}
+	return i.Station
+}
+
+// PrepTimeOrDefault returns the time it takes to prepare the item.
+func (i *Item) PrepTimeOrDefault() time.Duration {
+	if i.PrepTime <= 0 {
+		return DefaultPrepTime
+	}
+	return i.PrepTime
+}
+
 // load populates the fields in the receiver from the file passed as parameter.
-func loadMenu(file string) (*Menu, error) {
-	data, err := ioutil.ReadFile(file)
+func loadMenu(assets fs.FS, file string) (*Menu, error) {
+	data, err := fs.ReadFile(assets, file)
 	if err != nil {
 		return nil, err
 	}
@@ -1,8 +1,10 @@
 package courier
 
 import (
-	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
 	"net/http"
+
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
 )
 
 func (h *CourierService) addJob(w http.ResponseWriter, r *http.Request) {
@@ -17,9 +19,36 @@ func (h *CourierService) addJob(w http.ResponseWriter, r *http.Request) {
 		OrderID:         r.Form.Get("id"),
 		AcceptTaskToken: []byte(r.Form.Get("task_token")),
 		Status:          djPending,
+		Distance:        defaultDistance,
+	}
+	if job.Tip, err = h.orderTip(job.OrderID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// offer the job to the courier selected by dispatch, if any,
+	// falling back to any other available courier
+	if !h.assignJob(&job, r.Form.Get("courier")) {
+		http.Error(w, "No courier available for order: "+job.OrderID, http.StatusServiceUnavailable)
+		return
 	}
 
 	// store order
-	h.DeliveryQueue.Jobs[job.OrderID] = &job
-	common.ViewHandler(w, r, &h.DeliveryQueue)
+	err = h.putJob(&job)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.showJobs(w, r)
+}
+
+// orderTip returns the tip the customer added to the order, orders
+// without a price record have no tip.
+func (h *CourierService) orderTip(orderID string) (float32, error) {
+	var price common.OrderPrice
+	err := store.GetJSON(h.store, common.OrderPricesTable, orderID, &price)
+	if err == store.ErrNotFound {
+		return 0, nil
+	}
+	return price.Tip, err
 }
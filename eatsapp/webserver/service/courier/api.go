@@ -0,0 +1,116 @@
+package courier
+
+import (
+	"net/http"
+	"sort"
+
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+)
+
+type (
+	// JobResource models a delivery job returned by the JSON API,
+	// without the workflow task tokens.
+	JobResource struct {
+		OrderID       string
+		CourierID     string
+		Status        JobStatus
+		Distance      float32
+		Tip           float32
+		FailureReason string
+		Rating        int
+	}
+)
+
+// RegisterAPI adds the delivery job routes to the API.
+func (h *CourierService) RegisterAPI(api *common.API) {
+	api.Handle(&common.Route{
+		Method:   "GET",
+		Path:     "/jobs",
+		Summary:  "Lists the delivery jobs",
+		Query:    []string{"courier", "status"},
+		Response: []*JobResource{},
+		Roles:    []string{common.RoleCourier},
+		Handler:  h.listJobsAPI,
+	})
+	api.Handle(&common.Route{
+		Method:   "GET",
+		Path:     "/jobs/{id}",
+		Summary:  "Returns the delivery job of an order",
+		Response: &JobResource{},
+		Roles:    []string{common.RoleCourier},
+		Handler: func(r *http.Request, params map[string]string) (interface{}, error) {
+			return h.getJobResource(params["id"])
+		},
+	})
+	api.Handle(&common.Route{
+		Method:   "PATCH",
+		Path:     "/jobs/{id}",
+		Summary:  "Applies an action (accept, decline, picked_up, completed, failed, returned, disposed, rate) to a delivery job",
+		Query:    []string{"action", "reason", "rating"},
+		Response: &JobResource{},
+		Roles:    []string{common.RoleCourier},
+		Handler:  h.updateJobAPI,
+	})
+}
+
+func (h *CourierService) listJobsAPI(r *http.Request, params map[string]string) (interface{}, error) {
+	queue, err := h.queue()
+	if err != nil {
+		return nil, err
+	}
+
+	courierID := r.URL.Query().Get("courier")
+	status := JobStatus(r.URL.Query().Get("status"))
+	jobs := make([]*JobResource, 0, len(queue.Jobs))
+	for _, job := range queue.Jobs {
+		if len(courierID) > 0 && job.CourierID != courierID {
+			continue
+		}
+		if len(status) > 0 && job.Status != status {
+			continue
+		}
+		jobs = append(jobs, newJobResource(job))
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].OrderID < jobs[j].OrderID
+	})
+	return jobs, nil
+}
+
+// updateJobAPI applies the action through the same handler as
+// the courier page and returns the updated job.
+func (h *CourierService) updateJobAPI(r *http.Request, params map[string]string) (interface{}, error) {
+	if !JobsPolicy.Allows(common.CurrentUser(r), r) {
+		return nil, common.NewAPIError(http.StatusForbidden, "Action not allowed: "+r.URL.Query().Get("action"))
+	}
+	query := r.URL.Query()
+	query.Set("id", params["id"])
+	if _, err := common.Delegate(http.HandlerFunc(h.updateJob), r, query); err != nil {
+		return nil, err
+	}
+	return h.getJobResource(params["id"])
+}
+
+func (h *CourierService) getJobResource(orderID string) (*JobResource, error) {
+	job, err := h.getJob(orderID)
+	if err == store.ErrNotFound {
+		return nil, common.NewAPIError(http.StatusNotFound, "Job not found: "+orderID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newJobResource(job), nil
+}
+
+func newJobResource(job *DeliveryJob) *JobResource {
+	return &JobResource{
+		OrderID:       job.OrderID,
+		CourierID:     job.CourierID,
+		Status:        job.Status,
+		Distance:      job.Distance,
+		Tip:           job.Tip,
+		FailureReason: job.FailureReason,
+		Rating:        job.Rating,
+	}
+}
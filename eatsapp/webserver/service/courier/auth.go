@@ -0,0 +1,31 @@
+package courier
+
+import (
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+)
+
+// Policies authorizing the requests sent to the courier services,
+// requests without a role are sent by the workers.
+var (
+	JobsPolicy = common.Policy{
+		"GET":            {common.RoleCourier},
+		"POST":           nil,
+		"PATCH":          {common.RoleCourier},
+		"PATCH p_token":  nil,
+		"PATCH c_token":  nil,
+		"PATCH r_token":  nil,
+		"PATCH rate":     nil,
+		"PATCH retry":    nil,
+		"PATCH disposed": nil,
+	}
+	ShiftPolicy = common.Policy{
+		"GET":   {common.RoleCourier},
+		"POST":  nil,
+		"PATCH": {common.RoleCourier},
+	}
+	EarningsPolicy = common.Policy{
+		"GET":   {common.RoleCourier},
+		"POST":  nil,
+		"PATCH": nil,
+	}
+)
@@ -0,0 +1,174 @@
+package courier
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/message"
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+)
+
+type (
+	// FareModel stores the values used to compute courier earnings.
+	FareModel struct {
+		BaseFare float32 // flat amount paid for every delivery
+		PerKm    float32 // amount paid per km of delivery distance
+		TipShare float32 // fraction of the customer tip paid to the courier
+	}
+
+	// EarningsService implements the handlers for requests
+	// sent to the courier earnings http service
+	EarningsService struct {
+		couriers *CourierService
+		ledger   *Ledger
+		fare     FareModel
+	}
+
+	// CourierEarnings models the earnings of a single courier.
+	CourierEarnings struct {
+		CourierID string
+		Unpaid    float32
+		Paid      float32
+		Entries   []EarningsEntry
+	}
+
+	// EarningsPage models the data displayed on the courier earnings page.
+	EarningsPage struct {
+		Couriers []*CourierEarnings
+	}
+)
+
+// DefaultFareModel is the fare model used by the courier earnings service.
+var DefaultFareModel = FareModel{
+	BaseFare: 3,
+	PerKm:    0.8,
+	TipShare: 1,
+}
+
+// NewEarningsService returns a new instance of the EarningsService object.
+func NewEarningsService(couriers *CourierService, ledgerFile string, fare FareModel) *EarningsService {
+	ledger, err := NewLedger(ledgerFile)
+	if err != nil {
+		panic("error loading courier ledger: " + err.Error())
+	}
+	return &EarningsService{
+		couriers: couriers,
+		ledger:   ledger,
+		fare:     fare,
+	}
+}
+
+func (h *EarningsService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.showEarnings(w, r)
+	case "POST":
+		h.postEarnings(w, r)
+	case "PATCH":
+		h.settle(w, r)
+	default:
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *EarningsService) showEarnings(w http.ResponseWriter, r *http.Request) {
+	byCourier := make(map[string]*CourierEarnings)
+	page := EarningsPage{}
+	for _, e := range h.ledger.Entries() {
+		c, ok := byCourier[e.CourierID]
+		if !ok {
+			c = &CourierEarnings{CourierID: e.CourierID}
+			byCourier[e.CourierID] = c
+			page.Couriers = append(page.Couriers, c)
+		}
+		if len(e.PayoutID) > 0 {
+			c.Paid += e.Total
+		} else {
+			c.Unpaid += e.Total
+		}
+		c.Entries = append(c.Entries, e)
+	}
+	sort.Slice(page.Couriers, func(i, j int) bool {
+		return page.Couriers[i].CourierID < page.Couriers[j].CourierID
+	})
+	common.ViewHandler(w, r, &page)
+}
+
+// postEarnings records the earnings for the delivery job of the order
+// passed as the "id" form value, or for the leg of the delivery passed
+// as the "leg" form value, which is recorded as a separate entry.
+func (h *EarningsService) postEarnings(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	orderID := r.Form.Get("id")
+	job, err := h.couriers.getJob(orderID)
+	if err == store.ErrNotFound {
+		http.Error(w, "Order not found: "+orderID, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	leg := r.Form.Get("leg")
+	if len(leg) > 0 && leg != message.ReturnLeg {
+		http.Error(w, "Unknown delivery leg: "+leg, http.StatusUnprocessableEntity)
+		return
+	}
+
+	entry := h.fare.newEntry(job, leg, time.Now())
+	if err := h.ledger.Post(entry); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(entry)
+}
+
+// settle marks all the unpaid entries as paid and
+// responds with the payouts made to each courier.
+func (h *EarningsService) settle(w http.ResponseWriter, r *http.Request) {
+	action := r.URL.Query().Get("action")
+	if action != "settle" {
+		http.Error(w, "Unknown update action: "+action, http.StatusUnprocessableEntity)
+		return
+	}
+
+	payouts, err := h.ledger.Settle(time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(payouts)
+}
+
+// newEntry computes the earnings entry for a delivery job, or for a leg of
+// the job, whose entry ID is the order ID suffixed with the leg. The tip
+// share is paid with the delivery, which is only posted once delivered.
+func (f FareModel) newEntry(job *DeliveryJob, leg string, now time.Time) *EarningsEntry {
+	id := job.OrderID
+	if len(leg) > 0 {
+		id += "-" + leg
+	}
+	entry := &EarningsEntry{
+		ID:           id,
+		OrderID:      job.OrderID,
+		CourierID:    job.CourierID,
+		BaseFare:     f.BaseFare,
+		DistanceFare: job.Distance * f.PerKm,
+		CreatedAt:    now,
+	}
+	if len(leg) == 0 {
+		entry.TipShare = job.Tip * f.TipShare
+	}
+	entry.Total = entry.BaseFare + entry.DistanceFare + entry.TipShare
+	return entry
+}
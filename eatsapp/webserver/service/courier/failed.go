@@ -0,0 +1,52 @@
+package courier
+
+import (
+	"net/http"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/message"
+	"go.uber.org/cadence"
+)
+
+// handleDeliveryFailure takes the action corresponding to the specified
+// failed delivery action type, returning the call that completes the action
+// once the job is stored, and false for any other action.
+func (h *CourierService) handleDeliveryFailure(r *http.Request, job *DeliveryJob, action string) (func() error, bool) {
+	switch action {
+	case "failed":
+		// driver could not deliver the food, fail the DeliverOrderActivity
+		// with the reason so that the courier workflow can handle it
+		reason := r.URL.Query().Get("reason")
+		token := job.CompletTaskToken
+		job.FailureReason = reason
+		job.CompletTaskToken = nil
+		job.Status = djFailed
+		return func() error {
+			return h.client.CompleteActivity(token, nil,
+				cadence.NewCustomError(message.DeliveryAttemptFailedReason, reason))
+		}, true
+
+	case "retry":
+		// customer responded, the courier workflow retries the delivery
+		job.Status = djPickedUp
+
+	case "r_token":
+		// record the task token for ReturnOrderActivity
+		job.ReturnTaskToken = []byte(r.URL.Query().Get("task_token"))
+		job.Status = djReturning
+
+	case "returned":
+		// driver took the food back to the restaurant, complete ReturnOrderActivity
+		job.Status = djReturned
+		return func() error {
+			return h.client.CompleteActivity(job.ReturnTaskToken, djReturned, nil)
+		}, true
+
+	case "disposed":
+		// damaged food is disposed of by the driver
+		job.Status = djDisposed
+
+	default:
+		return nil, false
+	}
+	return nil, true
+}
@@ -3,6 +3,8 @@ package courier
 import (
 	"net/http"
 
+	"github.com/venkat1109/cadence-codelab/eatsapp/message"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
 	"go.uber.org/cadence"
 )
 
@@ -13,22 +15,30 @@ type (
 	// DeliveryJob is the struct storing metadata about a delivery job
 	DeliveryJob struct {
 		OrderID          string
+		CourierID        string
 		Status           JobStatus
+		Distance         float32
+		Tip              float32
+		FailureReason    string
+		Rating           int
 		AcceptTaskToken  []byte
 		PickupTaskToken  []byte
 		CompletTaskToken []byte
+		ReturnTaskToken  []byte
 	}
 
-	// DeliveryQueue is the struct modeling the list of jobs to be delivered.
+	// DeliveryQueue is the struct modeling the list of jobs to be delivered
+	// and the shifts of the couriers the jobs are offered to.
 	DeliveryQueue struct {
-		Jobs map[string]*DeliveryJob
+		Jobs   map[string]*DeliveryJob
+		Shifts map[string]*Shift
 	}
 
 	// CourierService implements the handlers for requests
 	// sent to the courier http service
 	CourierService struct {
-		client        cadence.Client
-		DeliveryQueue DeliveryQueue
+		client cadence.Client
+		store  store.Store
 	}
 )
 
@@ -38,16 +48,40 @@ const (
 	djAccepted            = "ACCEPTED"
 	djPickedUp            = "PICKED_UP"
 	djCompleted           = "COMPLETED"
+	djFailed              = "DELIVERY_FAILED"
+	djReturning           = "RETURNING"
+	djReturned            = "RETURNED"
+	djDisposed            = "DISPOSED"
+)
+
+const (
+	cadenceTaskList = "cadence-bistro"
+	// defaultCourierID is the courier registered with the courier service.
+	defaultCourierID = "john"
+	// defaultDistance is the delivery distance in km assumed for every job.
+	defaultDistance = 3.5
 )
 
 // NewService returns a new instance of the CourierService object.
-func NewService(c cadence.Client) *CourierService {
-	return &CourierService{
+func NewService(c cadence.Client, s store.Store) *CourierService {
+	h := &CourierService{
 		client: c,
-		DeliveryQueue: DeliveryQueue{
-			Jobs: make(map[string]*DeliveryJob),
-		},
+		store:  s,
+	}
+
+	// register the default courier on first start
+	_, err := s.Get(shiftsTable, defaultCourierID)
+	if err == store.ErrNotFound {
+		err = h.putShift(&Shift{
+			CourierID: defaultCourierID,
+			Status:    message.CSOffline,
+			Capacity:  defaultCapacity,
+		})
+	}
+	if err != nil {
+		panic("error loading courier shifts: " + err.Error())
 	}
+	return h
 }
 
 func (h *CourierService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,175 @@
+package courier
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/message"
+)
+
+type (
+	// EarningsEntry models the amount owed to a courier for a single delivery.
+	EarningsEntry struct {
+		ID           string
+		OrderID      string
+		CourierID    string
+		BaseFare     float32
+		DistanceFare float32
+		TipShare     float32
+		Total        float32
+		CreatedAt    time.Time
+		PayoutID     string
+	}
+
+	// Ledger is an append-only store of courier earnings and payouts.
+	// Every change is written to the backing file as a new record and
+	// the in-memory view is rebuilt by replaying the file on startup.
+	Ledger struct {
+		sync.Mutex
+		file    string
+		entries []*EarningsEntry
+		byID    map[string]*EarningsEntry
+		payouts []*message.Payout
+	}
+
+	// ledgerRecord is the unit of data appended to the ledger file.
+	ledgerRecord struct {
+		Entry  *EarningsEntry  `json:",omitempty"`
+		Payout *message.Payout `json:",omitempty"`
+	}
+)
+
+// NewLedger returns a ledger backed by the specified file, replaying
+// any records that were written to it before.
+func NewLedger(file string) (*Ledger, error) {
+	l := &Ledger{
+		file: file,
+		byID: make(map[string]*EarningsEntry),
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return l, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec ledgerRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, err
+		}
+		l.apply(&rec)
+	}
+	return l, scanner.Err()
+}
+
+// Post appends an earnings entry to the ledger. Posting an entry whose ID
+// is already in the ledger is a no-op, so that retried posts are safe.
+func (l *Ledger) Post(entry *EarningsEntry) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if _, ok := l.byID[entry.ID]; ok {
+		return nil
+	}
+	return l.append(&ledgerRecord{Entry: entry})
+}
+
+// Settle aggregates the unpaid entries per courier and records
+// a payout for each courier, marking those entries as paid. Payouts
+// are numbered in the order they are recorded in the ledger.
+func (l *Ledger) Settle(now time.Time) ([]*message.Payout, error) {
+	l.Lock()
+	defer l.Unlock()
+
+	byCourier := make(map[string]*message.Payout)
+	var payouts []*message.Payout
+	for _, e := range l.entries {
+		if len(e.PayoutID) > 0 {
+			continue
+		}
+		p, ok := byCourier[e.CourierID]
+		if !ok {
+			p = &message.Payout{
+				ID:        fmt.Sprintf("PO-%s-%d", e.CourierID, len(l.payouts)+len(payouts)+1),
+				CourierID: e.CourierID,
+				SettledAt: now,
+			}
+			byCourier[e.CourierID] = p
+			payouts = append(payouts, p)
+		}
+		p.Amount += e.Total
+		p.EntryIDs = append(p.EntryIDs, e.ID)
+	}
+
+	for _, p := range payouts {
+		if err := l.append(&ledgerRecord{Payout: p}); err != nil {
+			return nil, err
+		}
+	}
+	return payouts, nil
+}
+
+// Entries returns a snapshot of all the entries in the ledger.
+func (l *Ledger) Entries() []EarningsEntry {
+	l.Lock()
+	defer l.Unlock()
+
+	entries := make([]EarningsEntry, 0, len(l.entries))
+	for _, e := range l.entries {
+		entries = append(entries, *e)
+	}
+	return entries
+}
+
+// append writes the record to the ledger file and then applies it
+// to the in-memory view. Must be called with the lock held.
+func (l *Ledger) append(rec *ledgerRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.file), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+
+	l.apply(rec)
+	return nil
+}
+
+func (l *Ledger) apply(rec *ledgerRecord) {
+	if rec.Entry != nil {
+		l.entries = append(l.entries, rec.Entry)
+		l.byID[rec.Entry.ID] = rec.Entry
+	}
+	if rec.Payout != nil {
+		l.payouts = append(l.payouts, rec.Payout)
+		for _, id := range rec.Payout.EntryIDs {
+			if e, ok := l.byID[id]; ok {
+				e.PayoutID = rec.Payout.ID
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package courier
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/message"
+)
+
+func TestNewEntry(t *testing.T) {
+	fare := FareModel{BaseFare: 3, PerKm: 0.5, TipShare: 0.8}
+	tests := []struct {
+		name  string
+		job   DeliveryJob
+		leg   string
+		id    string
+		tip   float32
+		total float32
+	}{
+		{"delivery", DeliveryJob{OrderID: "o1", Distance: 4}, "", "o1", 0, 5},
+		{"delivery with tip", DeliveryJob{OrderID: "o2", Distance: 4, Tip: 5}, "", "o2", 4, 9},
+		{"return leg keeps the tip", DeliveryJob{OrderID: "o3", Distance: 2, Tip: 5}, message.ReturnLeg, "o3-return", 0, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := fare.newEntry(&tt.job, tt.leg, time.Now())
+			if entry.ID != tt.id {
+				t.Errorf("ID = %q, want %q", entry.ID, tt.id)
+			}
+			if entry.TipShare != tt.tip {
+				t.Errorf("TipShare = %v, want %v", entry.TipShare, tt.tip)
+			}
+			if entry.Total != tt.total {
+				t.Errorf("Total = %v, want %v", entry.Total, tt.total)
+			}
+		})
+	}
+}
+
+func TestLedgerSettle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ledger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "ledger.jsonl")
+
+	ledger, err := NewLedger(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := []*EarningsEntry{
+		{ID: "o1", CourierID: "alice", TipShare: 2, Total: 7},
+		{ID: "o2", CourierID: "bob", Total: 4},
+		{ID: "o3", CourierID: "alice", Total: 5},
+		{ID: "o1", CourierID: "alice", TipShare: 2, Total: 7}, // retried post
+	}
+	for _, e := range entries {
+		if err := ledger.Post(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	payouts, err := ledger.Settle(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		id      string
+		courier string
+		amount  float32
+		entries int
+	}{
+		{"PO-alice-1", "alice", 12, 2},
+		{"PO-bob-2", "bob", 4, 1},
+	}
+	if len(payouts) != len(want) {
+		t.Fatalf("got %d payouts, want %d", len(payouts), len(want))
+	}
+	for i, w := range want {
+		p := payouts[i]
+		if p.ID != w.id || p.CourierID != w.courier || p.Amount != w.amount || len(p.EntryIDs) != w.entries {
+			t.Errorf("payout %d = %+v, want %+v", i, p, w)
+		}
+	}
+
+	// replaying the file restores the paid entries, which are not paid twice
+	ledger, err = NewLedger(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range ledger.Entries() {
+		if len(e.PayoutID) == 0 {
+			t.Errorf("entry %s is not paid after replay", e.ID)
+		}
+	}
+	if err := ledger.Post(&EarningsEntry{ID: "o4", CourierID: "bob", Total: 3}); err != nil {
+		t.Fatal(err)
+	}
+	payouts, err = ledger.Settle(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payouts) != 1 || payouts[0].ID != "PO-bob-3" || payouts[0].Amount != 3 {
+		t.Errorf("payouts after replay = %+v, want a single PO-bob-3 of 3", payouts)
+	}
+}
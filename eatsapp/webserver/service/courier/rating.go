@@ -0,0 +1,59 @@
+package courier
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+)
+
+// rateJob records the rating given by the customer to the courier of a job.
+func (h *CourierService) rateJob(w http.ResponseWriter, r *http.Request, jobID string) {
+	rating, err := common.ParseRating(r.URL.Query().Get("rating"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	job, err := h.modifyJob(jobID, func(job *DeliveryJob) error {
+		job.Rating = rating
+		return nil
+	})
+	if err == store.ErrNotFound {
+		http.Error(w, "Order not found: "+jobID, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%+v", job)
+}
+
+// ratings returns the aggregated rating of every courier that has been rated.
+func (h *CourierService) ratings() (map[string]*common.Rating, error) {
+	jobs, err := h.store.List(jobsTable)
+	if err != nil {
+		return nil, err
+	}
+
+	ratings := make(map[string]*common.Rating)
+	for _, v := range jobs {
+		var job DeliveryJob
+		if err := json.Unmarshal(v, &job); err != nil {
+			return nil, err
+		}
+		if job.Rating == 0 {
+			continue
+		}
+		rating, ok := ratings[job.CourierID]
+		if !ok {
+			rating = &common.Rating{}
+			ratings[job.CourierID] = rating
+		}
+		rating.Add(job.Rating)
+	}
+	return ratings, nil
+}
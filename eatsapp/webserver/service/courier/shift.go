@@ -0,0 +1,306 @@
+package courier
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/message"
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+)
+
+type (
+	// Shift models the availability of a courier, as reported by the
+	// courier's shift workflow. Pending holds the jobs assigned to the
+	// courier that the shift workflow has not reported yet.
+	Shift struct {
+		CourierID     string
+		Status        string
+		Capacity      int
+		ActiveJobs    int
+		Jobs          []string             `json:",omitempty"`
+		Pending       map[string]time.Time `json:",omitempty"`
+		StartedAt     time.Time
+		EndedAt       time.Time
+		JobsCompleted int
+		Breaks        int
+		BreakTime     time.Duration
+		Rating        float32 `json:",omitempty"`
+		Ratings       int     `json:",omitempty"`
+	}
+
+	// ShiftService implements the handlers for requests
+	// sent to the courier shift http service
+	ShiftService struct {
+		couriers *CourierService
+	}
+)
+
+const (
+	// defaultCapacity is the maximum number of jobs a courier handles at once.
+	defaultCapacity = 2
+	// pendingTimeout is how long a job assigned to a courier is counted
+	// before the shift workflow reports it, after which the assignment is
+	// assumed to have been rejected by the workflow.
+	pendingTimeout = time.Minute
+)
+
+var errCourierUnavailable = errors.New("courier unavailable")
+
+// NewShiftService returns a new instance of the ShiftService object.
+func NewShiftService(couriers *CourierService) *ShiftService {
+	return &ShiftService{
+		couriers: couriers,
+	}
+}
+
+func (h *ShiftService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.listShifts(w, r)
+	case "POST":
+		h.reportShift(w, r)
+	case "PATCH":
+		h.updateShift(w, r)
+	default:
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+}
+
+// listShifts responds with the availability and rating of all couriers.
+func (h *ShiftService) listShifts(w http.ResponseWriter, r *http.Request) {
+	q, err := h.couriers.queue()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	shifts := make([]*Shift, 0, len(q.Shifts))
+	for _, shift := range q.Shifts {
+		shifts = append(shifts, shift)
+	}
+	sort.Slice(shifts, func(i, j int) bool {
+		return shifts[i].CourierID < shifts[j].CourierID
+	})
+	json.NewEncoder(w).Encode(shifts)
+}
+
+// reportShift records the shift report sent by the shift workflow. The
+// report may have been sent before the workflow received the latest jobs
+// assigned to the courier, which are kept until reported.
+func (h *ShiftService) reportShift(w http.ResponseWriter, r *http.Request) {
+	var report Shift
+	err := json.NewDecoder(r.Body).Decode(&report)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	shift, err := h.couriers.modifyShift(report.CourierID, func(shift *Shift) error {
+		shift.merge(&report, time.Now())
+		return nil
+	})
+	if err == store.ErrNotFound {
+		shift, err = &report, h.couriers.putShift(&report)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%+v", *shift)
+}
+
+// updateShift starts or signals the shift workflow of a courier.
+func (h *ShiftService) updateShift(w http.ResponseWriter, r *http.Request) {
+	courierID := r.URL.Query().Get("courier")
+	var shift Shift
+	err := store.GetJSON(h.couriers.store, shiftsTable, courierID, &shift)
+	if err == store.ErrNotFound {
+		http.Error(w, "Courier not found: "+courierID, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	switch action := r.URL.Query().Get("action"); action {
+	case "online":
+		err = h.couriers.startShift(courierID)
+	case "break":
+		err = h.couriers.signalShift(courierID, message.SEBreak, "")
+	case "resume":
+		err = h.couriers.signalShift(courierID, message.SEResume, "")
+	case "offline":
+		err = h.couriers.signalShift(courierID, message.SEOffline, "")
+	default:
+		http.Error(w, "Unknown update action: "+action, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%+v", shift)
+}
+
+// merge applies the report of the shift workflow to the shift, counting
+// the jobs assigned to the courier that are not reported yet.
+func (s *Shift) merge(report *Shift, now time.Time) {
+	pending := s.Pending
+	*s = *report
+	s.Pending = nil
+
+	reported := make(map[string]bool, len(report.Jobs))
+	for _, id := range report.Jobs {
+		reported[id] = true
+	}
+	for id, assignedAt := range pending {
+		if reported[id] || now.Sub(assignedAt) > pendingTimeout {
+			continue
+		}
+		if s.Pending == nil {
+			s.Pending = make(map[string]time.Time)
+		}
+		s.Pending[id] = assignedAt
+		s.ActiveJobs++
+	}
+	if s.Status == message.CSAvailable && s.ActiveJobs >= s.Capacity {
+		s.Status = message.CSBusy
+	}
+}
+
+// Length returns the time the courier has been online.
+func (s *Shift) Length() time.Duration {
+	if s.StartedAt.IsZero() {
+		return 0
+	}
+	if s.EndedAt.IsZero() {
+		return time.Since(s.StartedAt)
+	}
+	return s.EndedAt.Sub(s.StartedAt)
+}
+
+// startShift starts the shift workflow for the courier, a courier
+// that is already online is left as is.
+func (h *CourierService) startShift(courierID string) error {
+	workflowOptions := cadence.StartWorkflowOptions{
+		ID:                              shiftWorkflowID(courierID),
+		TaskList:                        cadenceTaskList,
+		ExecutionStartToCloseTimeout:    message.ShiftWorkflowTimeout,
+		DecisionTaskStartToCloseTimeout: time.Minute,
+	}
+	state := &message.ShiftStart{
+		CourierID: courierID,
+		Capacity:  defaultCapacity,
+	}
+
+	_, err := h.client.StartWorkflow(workflowOptions, message.ShiftWorkflowName, state)
+	if err != nil && !strings.HasPrefix(err.Error(), "WorkflowExecutionAlreadyStartedError") {
+		return err
+	}
+	return nil
+}
+
+// signalShift sends a shift event to the running shift workflow of the courier.
+func (h *CourierService) signalShift(courierID string, eventType message.ShiftEventType, orderID string) error {
+	event := message.ShiftEvent{
+		Type:    eventType,
+		OrderID: orderID,
+	}
+	return h.client.SignalWorkflow(shiftWorkflowID(courierID), "", message.ShiftSignalName, event)
+}
+
+// assignJob offers the job to the preferred courier if available, and
+// otherwise to the first courier that is, returning false when all
+// couriers are offline, on break or at capacity.
+func (h *CourierService) assignJob(job *DeliveryJob, preferred string) bool {
+	shifts, err := h.store.List(shiftsTable)
+	if err != nil {
+		common.Logger().Error("Failed to list courier shifts", zap.String("order", job.OrderID), zap.Error(err))
+		return false
+	}
+
+	// move the preferred courier to the front, so that it is offered first
+	ids := store.SortedKeys(shifts)
+	for i, id := range ids {
+		if id == preferred {
+			copy(ids[1:i+1], ids[:i])
+			ids[0] = preferred
+			break
+		}
+	}
+	for _, id := range ids {
+		// count the job right away, the shift workflow
+		// reports the authoritative value shortly after
+		var prevStatus string
+		_, err := h.modifyShift(id, func(shift *Shift) error {
+			if shift.Status != message.CSAvailable || shift.ActiveJobs >= shift.Capacity {
+				return errCourierUnavailable
+			}
+			prevStatus = shift.Status
+			shift.ActiveJobs++
+			if shift.ActiveJobs >= shift.Capacity {
+				shift.Status = message.CSBusy
+			}
+			if shift.Pending == nil {
+				shift.Pending = make(map[string]time.Time)
+			}
+			shift.Pending[job.OrderID] = time.Now()
+			return nil
+		})
+		if err != nil {
+			continue
+		}
+
+		if err := h.signalShift(id, message.SEJobAssigned, job.OrderID); err != nil {
+			common.Logger().Error("Failed to assign job to courier", zap.String("courier", id), zap.String("order", job.OrderID), zap.Error(err))
+			h.modifyShift(id, func(shift *Shift) error {
+				if _, ok := shift.Pending[job.OrderID]; !ok {
+					return nil
+				}
+				delete(shift.Pending, job.OrderID)
+				shift.ActiveJobs--
+				if shift.Status == message.CSBusy {
+					shift.Status = prevStatus
+				}
+				return nil
+			})
+			continue
+		}
+		job.CourierID = id
+		return true
+	}
+	return false
+}
+
+// releaseJob notifies the shift workflow once a job is no longer active.
+func (h *CourierService) releaseJob(job *DeliveryJob, prevStatus JobStatus) {
+	if job.Status == prevStatus || len(job.CourierID) == 0 {
+		return
+	}
+
+	var err error
+	switch job.Status {
+	case djCompleted:
+		err = h.signalShift(job.CourierID, message.SEJobCompleted, job.OrderID)
+	case djRejected, djReturned, djDisposed:
+		err = h.signalShift(job.CourierID, message.SEJobReleased, job.OrderID)
+	}
+	if err != nil {
+		common.Logger().Error("Failed to release job of courier", zap.String("courier", job.CourierID), zap.String("order", job.OrderID), zap.Error(err))
+	}
+}
+
+func shiftWorkflowID(courierID string) string {
+	return "SHIFT_" + courierID
+}
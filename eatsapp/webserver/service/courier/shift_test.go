@@ -0,0 +1,65 @@
+package courier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/message"
+)
+
+func TestShiftMerge(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		pending map[string]time.Time
+		report  Shift
+		status  string
+		active  int
+		left    int
+	}{
+		{
+			name:   "no pending jobs",
+			report: Shift{Status: message.CSAvailable, Capacity: 2, ActiveJobs: 1, Jobs: []string{"o1"}},
+			status: message.CSAvailable, active: 1,
+		},
+		{
+			name:    "stale report",
+			pending: map[string]time.Time{"o2": now},
+			report:  Shift{Status: message.CSAvailable, Capacity: 2, ActiveJobs: 1, Jobs: []string{"o1"}},
+			status:  message.CSBusy, active: 2, left: 1,
+		},
+		{
+			name:    "reported job",
+			pending: map[string]time.Time{"o2": now},
+			report:  Shift{Status: message.CSBusy, Capacity: 2, ActiveJobs: 2, Jobs: []string{"o1", "o2"}},
+			status:  message.CSBusy, active: 2,
+		},
+		{
+			name:    "rejected job",
+			pending: map[string]time.Time{"o2": now.Add(-2 * pendingTimeout)},
+			report:  Shift{Status: message.CSAvailable, Capacity: 2, ActiveJobs: 1, Jobs: []string{"o1"}},
+			status:  message.CSAvailable, active: 1,
+		},
+		{
+			name:    "on break",
+			pending: map[string]time.Time{"o2": now},
+			report:  Shift{Status: message.CSOnBreak, Capacity: 2},
+			status:  message.CSOnBreak, active: 1, left: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shift := Shift{Status: message.CSAvailable, Capacity: 2, Pending: tt.pending}
+			shift.merge(&tt.report, now)
+			if shift.Status != tt.status {
+				t.Errorf("status = %s, want %s", shift.Status, tt.status)
+			}
+			if shift.ActiveJobs != tt.active {
+				t.Errorf("active jobs = %d, want %d", shift.ActiveJobs, tt.active)
+			}
+			if len(shift.Pending) != tt.left {
+				t.Errorf("pending = %v, want %d jobs", shift.Pending, tt.left)
+			}
+		})
+	}
+}
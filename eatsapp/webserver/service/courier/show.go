@@ -6,5 +6,10 @@ import (
 )
 
 func (h *CourierService) showJobs(w http.ResponseWriter, r *http.Request) {
-	common.ViewHandler(w, r, &h.DeliveryQueue)
+	queue, err := h.queue()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	common.ViewHandler(w, r, queue)
 }
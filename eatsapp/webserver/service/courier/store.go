@@ -0,0 +1,127 @@
+package courier
+
+import (
+	"encoding/json"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+)
+
+const (
+	jobsTable   = "courier-jobs"
+	shiftsTable = "courier-shifts"
+)
+
+func (h *CourierService) getJob(orderID string) (*DeliveryJob, error) {
+	var job DeliveryJob
+	err := store.GetJSON(h.store, jobsTable, orderID, &job)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// readJob returns the stored job along with its record, for saveJob.
+func (h *CourierService) readJob(orderID string) (*DeliveryJob, []byte, error) {
+	data, err := h.store.Get(jobsTable, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var job DeliveryJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, nil, err
+	}
+	return &job, data, nil
+}
+
+// saveJob atomically stores the changes made to the job since its record
+// was read by readJob, store.ErrConflict if they were changed since.
+func (h *CourierService) saveJob(job *DeliveryJob, record []byte) error {
+	return store.MergeJSON(h.store, jobsTable, job.OrderID, record, job)
+}
+
+// rollbackJob restores the job stored by saveJob to its previous value,
+// when the call that completes the change failed.
+func (h *CourierService) rollbackJob(job *DeliveryJob, prev *DeliveryJob) error {
+	record, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return store.MergeJSON(h.store, jobsTable, job.OrderID, record, prev)
+}
+
+func (h *CourierService) putJob(job *DeliveryJob) error {
+	return store.PutJSON(h.store, jobsTable, job.OrderID, job)
+}
+
+// modifyJob atomically applies fn to the stored job and returns the result.
+func (h *CourierService) modifyJob(orderID string, fn func(job *DeliveryJob) error) (*DeliveryJob, error) {
+	var job DeliveryJob
+	err := store.UpdateJSON(h.store, jobsTable, orderID, &job, func() error {
+		return fn(&job)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (h *CourierService) putShift(shift *Shift) error {
+	return store.PutJSON(h.store, shiftsTable, shift.CourierID, shift)
+}
+
+// modifyShift atomically applies fn to the stored shift and returns the result.
+func (h *CourierService) modifyShift(courierID string, fn func(shift *Shift) error) (*Shift, error) {
+	var shift Shift
+	err := store.UpdateJSON(h.store, shiftsTable, courierID, &shift, func() error {
+		return fn(&shift)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &shift, nil
+}
+
+// queue returns a snapshot of the stored jobs and shifts.
+func (h *CourierService) queue() (*DeliveryQueue, error) {
+	q := &DeliveryQueue{
+		Jobs:   make(map[string]*DeliveryJob),
+		Shifts: make(map[string]*Shift),
+	}
+
+	jobs, err := h.store.List(jobsTable)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range jobs {
+		var job DeliveryJob
+		if err := json.Unmarshal(v, &job); err != nil {
+			return nil, err
+		}
+		q.Jobs[k] = &job
+	}
+
+	shifts, err := h.store.List(shiftsTable)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range shifts {
+		var shift Shift
+		if err := json.Unmarshal(v, &shift); err != nil {
+			return nil, err
+		}
+		q.Shifts[k] = &shift
+	}
+
+	// ratings are derived from the jobs rather than reported by the shift
+	ratings, err := h.ratings()
+	if err != nil {
+		return nil, err
+	}
+	for id, rating := range ratings {
+		if shift, ok := q.Shifts[id]; ok {
+			shift.Rating = rating.Average()
+			shift.Ratings = rating.Count
+		}
+	}
+	return q, nil
+}
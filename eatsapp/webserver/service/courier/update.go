@@ -4,27 +4,65 @@ import (
 	//"errors"
 	"fmt"
 	"net/http"
+
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+	"go.uber.org/zap"
 )
 
 func (h *CourierService) updateJob(w http.ResponseWriter, r *http.Request) {
 	jobID := r.URL.Query().Get("id")
-	job, ok := h.DeliveryQueue.Jobs[jobID]
-	if !ok {
-		http.Error(w, "Order not found: "+jobID, http.StatusNotFound)
-		return
-	}
-
 	action := r.URL.Query().Get("action")
 	if len(action) == 0 {
 		http.Error(w, "No update action specified! "+action, http.StatusUnprocessableEntity)
 		return
 	}
+	if action == "rate" {
+		h.rateJob(w, r, jobID)
+		return
+	}
+
+	// the actions complete activities, which is not done holding the store
+	// and cannot be undone, so the job is stored before they are completed
+	job, record, err := h.readJob(jobID)
+	if err == store.ErrNotFound {
+		http.Error(w, "Order not found: "+jobID, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	prev := *job
+	complete, ok := h.handleDeliveryFailure(r, job, action)
+	if !ok {
+		complete = h.handleAction(r, job, action)
+	}
+	err = h.saveJob(job, record)
+	if err == store.ErrConflict {
+		http.Error(w, "Order changed concurrently: "+jobID, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if complete != nil {
+		if err := complete(); err != nil {
+			if rerr := h.rollbackJob(job, &prev); rerr != nil {
+				common.Logger().Error("Failed to roll back job", zap.String("order", jobID), zap.Error(rerr))
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
 
-	h.handleAction(r, job, action)
-	fmt.Fprintf(w, "%s", job)
+	h.releaseJob(job, prev.Status)
+	fmt.Fprintf(w, "%+v", job)
 }
 
-// handleAction takes the action corresponding to the specified action type
-func (h *CourierService) handleAction(r *http.Request, job *DeliveryJob, action string) {
-	return
+// handleAction takes the action corresponding to the specified action type,
+// returning the call that completes the action once the job is stored
+func (h *CourierService) handleAction(r *http.Request, job *DeliveryJob, action string) func() error {
+	return nil
 }
@@ -0,0 +1,85 @@
+package courier
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+	"go.uber.org/cadence"
+)
+
+// fakeClient records the completed activities, failing them with err, and
+// drops the signals, the other client methods are not used by the tests.
+type fakeClient struct {
+	cadence.Client
+	err       error
+	completed [][]byte
+}
+
+func (c *fakeClient) CompleteActivity(taskToken []byte, result interface{}, err error) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.completed = append(c.completed, taskToken)
+	return nil
+}
+
+func (c *fakeClient) SignalWorkflow(workflowID string, runID string, signalName string, arg interface{}) error {
+	return nil
+}
+
+func TestUpdateJobCompletesAfterStoring(t *testing.T) {
+	tests := []struct {
+		name      string
+		action    string
+		err       error
+		code      int
+		status    JobStatus
+		completed int
+	}{
+		{"failed", "failed&reason=nobody+home", nil, 200, djFailed, 1},
+		{"failed not completed", "failed&reason=nobody+home", errors.New("activity timed out"), 500, djPickedUp, 0},
+		{"returned", "returned", nil, 200, djReturned, 1},
+		{"returned not completed", "returned", errors.New("activity timed out"), 500, djReturning, 0},
+		{"retry", "retry", nil, 200, djPickedUp, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeClient{err: tt.err}
+			h := NewService(client, store.NewMemoryStore())
+			job := &DeliveryJob{
+				OrderID:          "o1",
+				CourierID:        "john",
+				Status:           djPickedUp,
+				CompletTaskToken: []byte("deliver"),
+			}
+			if tt.action == "returned" {
+				job.Status = djReturning
+				job.ReturnTaskToken = []byte("return")
+			}
+			if err := h.putJob(job); err != nil {
+				t.Fatal(err)
+			}
+
+			w := httptest.NewRecorder()
+			h.updateJob(w, httptest.NewRequest("PATCH", "/courier?id=o1&action="+tt.action, nil))
+			if w.Code != tt.code {
+				t.Fatalf("status code = %d, want %d: %s", w.Code, tt.code, w.Body.String())
+			}
+			if len(client.completed) != tt.completed {
+				t.Errorf("completed %d activities, want %d", len(client.completed), tt.completed)
+			}
+			got, err := h.getJob("o1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Status != tt.status {
+				t.Errorf("job status = %s, want %s", got.Status, tt.status)
+			}
+			if tt.err != nil && string(got.CompletTaskToken) != "deliver" {
+				t.Errorf("job task token = %q, want it restored", got.CompletTaskToken)
+			}
+		})
+	}
+}
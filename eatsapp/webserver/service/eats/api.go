@@ -0,0 +1,195 @@
+package eats
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+)
+
+type (
+	// NewOrderRequest models the body of the API request placing an order.
+	NewOrderRequest struct {
+		Items        []string
+		Customer     string
+		AddressID    string
+		RedeemPoints int
+		Tip          float32
+	}
+
+	// OrderSummary models an eats order listed by the JSON API.
+	OrderSummary struct {
+		ID          string
+		RunID       string
+		StartedAt   time.Time
+		CloseStatus string `json:",omitempty"`
+	}
+
+	// OrderListResource models a page of the eats orders listed by the
+	// JSON API, NextPage is the next_page param of the next page.
+	OrderListResource struct {
+		Orders   []*OrderSummary
+		NextPage string `json:",omitempty"`
+	}
+
+	// OrderStatusResource models the status of an eats order returned
+	// by the JSON API, along with the requests awaiting the customer.
+	OrderStatusResource struct {
+		ID               string
+		RunID            string
+		Status           TaskGroupStatus
+		Reason           string
+		Tasks            []*Task
+		Contact          *DeliveryContact
+		Substitution     *SubstitutionRequest
+		Changes          *ChangesRequest
+		AwaitingFeedback bool
+	}
+)
+
+// RegisterAPI adds the eats order routes to the API.
+func (h *EatsService) RegisterAPI(api *common.API) {
+	api.Handle(&common.Route{
+		Method:   "GET",
+		Path:     "/eats/orders",
+		Summary:  "Lists the eats orders, the open ones of the past ten hours by default",
+		Query:    []string{"tab", "from", "to", "status", "type", "sort", "page_size", "next_page"},
+		Response: &OrderListResource{},
+		Roles:    []string{common.RoleCustomer},
+		Handler:  h.listOrdersAPI,
+	})
+	api.Handle(&common.Route{
+		Method:   "POST",
+		Path:     "/eats/orders",
+		Summary:  "Places an eats order",
+		Body:     &NewOrderRequest{},
+		Response: &OrderSummary{},
+		Status:   http.StatusCreated,
+		Roles:    []string{common.RoleCustomer},
+		Handler:  h.createAPI,
+	})
+	api.Handle(&common.Route{
+		Method:   "GET",
+		Path:     "/eats/orders/{id}",
+		Summary:  "Returns the status of an eats order",
+		Query:    []string{"run_id"},
+		Response: &OrderStatusResource{},
+		Roles:    []string{common.RoleCustomer},
+		Handler:  h.showOrderAPI,
+	})
+}
+
+// listOrdersAPI lists a page of the orders the user can access, with
+// the filters of the order list page.
+func (h *EatsService) listOrdersAPI(r *http.Request, params map[string]string) (interface{}, error) {
+	filter, err := parseOrderListFilter(r)
+	if err != nil {
+		return nil, common.NewAPIError(http.StatusUnprocessableEntity, err.Error())
+	}
+	executions, nextPage, err := h.listOrderPage(r, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &OrderListResource{
+		Orders:   make([]*OrderSummary, 0, len(executions)),
+		NextPage: nextPage,
+	}
+	for _, e := range executions {
+		order := &OrderSummary{
+			ID:        *e.Execution.WorkflowId,
+			RunID:     *e.Execution.RunId,
+			StartedAt: time.Unix(0, *e.StartTime),
+		}
+		if e.CloseStatus != nil {
+			order.CloseStatus = e.CloseStatus.String()
+		}
+		list.Orders = append(list.Orders, order)
+	}
+	return list, nil
+}
+
+// createAPI places the order through the same handler as the menu
+// page, which redirects to the status page of the new order. Customers
+// order for themselves, only the other roles may set the Customer.
+func (h *EatsService) createAPI(r *http.Request, params map[string]string) (interface{}, error) {
+	var order NewOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		return nil, common.NewAPIError(http.StatusBadRequest, "Invalid order: "+err.Error())
+	}
+	user := common.CurrentUser(r)
+	if user != nil && user.Role == common.RoleCustomer && len(order.Customer) > 0 && order.Customer != user.Name {
+		return nil, common.NewAPIError(http.StatusForbidden, "Customers cannot order for another customer")
+	}
+
+	form := url.Values{"item-id": order.Items}
+	if len(order.Customer) > 0 {
+		form.Set("customer", order.Customer)
+	}
+	if len(order.AddressID) > 0 {
+		form.Set("address_id", order.AddressID)
+	}
+	if order.RedeemPoints > 0 {
+		form.Set("redeem_points", strconv.Itoa(order.RedeemPoints))
+	}
+	if order.Tip != 0 {
+		form.Set("tip", strconv.FormatFloat(float64(order.Tip), 'f', -1, 32))
+	}
+	req, err := http.NewRequest("POST", r.URL.String(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	// the context carries the user the order is placed for
+	req = req.WithContext(r.Context())
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	header, err := common.Delegate(http.HandlerFunc(h.create), req, url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	location, err := url.Parse(header.Get("Location"))
+	if err != nil {
+		return nil, err
+	}
+	if location.Query().Get("error") == "order_exist" {
+		return nil, common.NewAPIError(http.StatusConflict, "Order already exists")
+	}
+	return &OrderSummary{
+		ID:        location.Query().Get("id"),
+		RunID:     location.Query().Get("run_id"),
+		StartedAt: time.Now(),
+	}, nil
+}
+
+func (h *EatsService) showOrderAPI(r *http.Request, params map[string]string) (interface{}, error) {
+	orderID := params["id"]
+	runID := r.URL.Query().Get("run_id")
+	if len(runID) == 0 {
+		return nil, common.NewAPIError(http.StatusUnprocessableEntity, "No run_id specified!")
+	}
+	if !h.canAccessOrder(r, orderID) {
+		return nil, common.NewAPIError(http.StatusNotFound, "Order not found: "+orderID)
+	}
+
+	group, err := h.processExecution(orderID, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &OrderStatusResource{
+		ID:               group.ID,
+		RunID:            group.RunID,
+		Status:           group.Status,
+		Reason:           group.Reason,
+		Tasks:            group.Tasks,
+		AwaitingFeedback: awaitingFeedback(group),
+	}
+	status.Contact, _ = h.getContact(orderID)
+	status.Substitution, _ = h.getSubstitution(orderID)
+	status.Changes, _ = h.getChanges(orderID)
+	return status, nil
+}
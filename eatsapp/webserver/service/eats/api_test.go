@@ -0,0 +1,173 @@
+package eats
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	config "github.com/venkat1109/cadence-codelab/common"
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/eats"
+	restaurantwf "github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/restaurant"
+	"go.uber.org/cadence"
+	s "go.uber.org/cadence/.gen/go/shared"
+)
+
+// fakeClient lists the open and closed workflows it is given, the other
+// client methods are not used by the tests.
+type fakeClient struct {
+	cadence.Client
+	open   []*s.WorkflowExecutionInfo
+	closed []*s.WorkflowExecutionInfo
+}
+
+func (c *fakeClient) ListOpenWorkflow(req *s.ListOpenWorkflowExecutionsRequest) (*s.ListOpenWorkflowExecutionsResponse, error) {
+	return &s.ListOpenWorkflowExecutionsResponse{Executions: c.open}, nil
+}
+
+// ListClosedWorkflow rejects the requests with several filters, as cadence does.
+func (c *fakeClient) ListClosedWorkflow(req *s.ListClosedWorkflowExecutionsRequest) (*s.ListClosedWorkflowExecutionsResponse, error) {
+	if req.StatusFilter != nil && req.TypeFilter != nil {
+		return nil, errors.New("only one of ExecutionFilter, TypeFilter or StatusFilter is allowed")
+	}
+	var executions []*s.WorkflowExecutionInfo
+	for _, e := range c.closed {
+		if req.StatusFilter != nil && *req.StatusFilter != *e.CloseStatus {
+			continue
+		}
+		if req.TypeFilter != nil && *req.TypeFilter.Name != *e.Type.Name {
+			continue
+		}
+		executions = append(executions, e)
+	}
+	return &s.ListClosedWorkflowExecutionsResponse{Executions: executions}, nil
+}
+
+func testExecution(id string, workflow interface{}, status *s.WorkflowExecutionCloseStatus) *s.WorkflowExecutionInfo {
+	runID := id + "-run"
+	name := getWorkflowName(workflow)
+	start := time.Now().Add(-time.Hour).UnixNano()
+	return &s.WorkflowExecutionInfo{
+		Execution:   &s.WorkflowExecution{WorkflowId: &id, RunId: &runID},
+		Type:        &s.WorkflowType{Name: &name},
+		StartTime:   &start,
+		CloseStatus: status,
+	}
+}
+
+// testAPI returns the API handler of the eats service, authenticated
+// as in the webserver, and the session cookie of each customer.
+func testAPI(t *testing.T, client cadence.Client, customers ...string) (http.Handler, map[string]*http.Cookie) {
+	st := store.NewMemoryStore()
+	cookies := make(map[string]*http.Cookie)
+	for _, customer := range customers {
+		session := common.Session{
+			ID:        "session-" + customer,
+			User:      common.User{Name: customer, Role: common.RoleCustomer},
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+		if err := store.PutJSON(st, "auth-sessions", session.ID, &session); err != nil {
+			t.Fatal(err)
+		}
+		cookies[customer] = &http.Cookie{Name: "bistro_session", Value: session.ID}
+	}
+
+	auth, err := common.NewAuth(config.AuthConfig{}, st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := common.NewAPI("/api/v1", "test", "1.0.0")
+	NewService(client, nil, nil, config.LoyaltyConfig{}, st).RegisterAPI(api)
+	return auth.Authenticate(api), cookies
+}
+
+func TestIsCustomerOrder(t *testing.T) {
+	tests := []struct {
+		orderID  string
+		customer string
+		want     bool
+	}{
+		{"EO-USR-ALICE-1500000000", "alice", true},
+		{"EO-USR-ALICE-1500000000", "bob", false},
+		{"EO-USR-AL-1-1500000000", "al", false},
+		{"EO-USR-AL-1-1500000000", "al-1", true},
+		{"EO-USR-ALICE-", "alice", false},
+		{"SHIFT_alice", "alice", false},
+	}
+	for _, tt := range tests {
+		if got := isCustomerOrder(tt.orderID, tt.customer); got != tt.want {
+			t.Errorf("isCustomerOrder(%q, %q) = %v, want %v", tt.orderID, tt.customer, got, tt.want)
+		}
+	}
+}
+
+func TestListOrdersAPI(t *testing.T) {
+	completed := s.WorkflowExecutionCloseStatus_COMPLETED
+	failed := s.WorkflowExecutionCloseStatus_FAILED
+	client := &fakeClient{
+		open: []*s.WorkflowExecutionInfo{
+			testExecution("EO-USR-ALICE-1", eats.OrderWorkflow, nil),
+			testExecution("EO-USR-BOB-2", eats.OrderWorkflow, nil),
+		},
+		closed: []*s.WorkflowExecutionInfo{
+			testExecution("EO-USR-ALICE-3", eats.OrderWorkflow, &completed),
+			testExecution("EO-USR-ALICE-4", eats.OrderWorkflow, &failed),
+			testExecution("EO-USR-ALICE-6", restaurantwf.OrderWorkflow, &failed),
+			testExecution("EO-USR-BOB-5", eats.OrderWorkflow, &completed),
+		},
+	}
+	handler, cookies := testAPI(t, client, "alice", "bob")
+
+	tests := []struct {
+		name     string
+		customer string
+		query    string
+		status   int
+		orders   []string
+	}{
+		{"open by default", "alice", "", http.StatusOK, []string{"EO-USR-ALICE-1"}},
+		{"other customer", "bob", "", http.StatusOK, []string{"EO-USR-BOB-2"}},
+		{"closed tab", "alice", "?tab=closed", http.StatusOK, []string{"EO-USR-ALICE-3", "EO-USR-ALICE-4"}},
+		{"closed status", "alice", "?tab=closed&status=failed", http.StatusOK, []string{"EO-USR-ALICE-4"}},
+		{"all tabs", "bob", "?tab=all", http.StatusOK, []string{"EO-USR-BOB-2", "EO-USR-BOB-5"}},
+		{"unknown tab", "alice", "?tab=recent", http.StatusUnprocessableEntity, nil},
+		{"open status", "alice", "?status=completed", http.StatusUnprocessableEntity, nil},
+		{"not logged in", "", "", http.StatusUnauthorized, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/v1/eats/orders"+tt.query, nil)
+			if cookie, ok := cookies[tt.customer]; ok {
+				r.AddCookie(cookie)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var list OrderListResource
+			if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+				t.Fatal(err)
+			}
+			if len(list.Orders) != len(tt.orders) {
+				t.Fatalf("got %d orders, want %v", len(list.Orders), tt.orders)
+			}
+			for i, order := range list.Orders {
+				if order.ID != tt.orders[i] {
+					t.Errorf("order %d = %s, want %s", i, order.ID, tt.orders[i])
+				}
+			}
+			if len(list.NextPage) > 0 {
+				t.Errorf("next page = %q, want none", list.NextPage)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package eats
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/eats"
+)
+
+var errOrderNotFound = errors.New("order not found")
+
+// Policies authorizing the requests sent to the eats services, requests
+// without a role are sent by the workers.
+var (
+	OrdersPolicy = common.Policy{
+		"GET":  {common.RoleCustomer},
+		"POST": {common.RoleCustomer},
+	}
+	MenuPolicy = common.Policy{
+		"GET": {common.RoleCustomer},
+	}
+	// ResponsePolicy authorizes the customer responses to the requests
+	// sent by the workflows: delivery contact, substitution, changes and
+	// feedback.
+	ResponsePolicy = common.Policy{
+		"POST":  nil,
+		"PATCH": {common.RoleCustomer},
+	}
+	CustomerPolicy = common.Policy{
+		"GET":  {common.RoleCustomer},
+		"POST": {common.RoleCustomer},
+	}
+	HistoryPolicy = common.Policy{
+		"GET":  {common.RoleCustomer},
+		"POST": {common.RoleCustomer},
+	}
+	PricePolicy = common.Policy{
+		"GET":   {common.RoleCustomer},
+		"PATCH": nil,
+	}
+	LoyaltyPolicy = common.Policy{
+		"GET":   {common.RoleCustomer},
+		"POST":  nil,
+		"PATCH": nil,
+	}
+)
+
+// orderingCustomer returns the customer placing an order. Customers order for
+// themselves, admins and the workers may order for the "customer" param.
+func orderingCustomer(r *http.Request) string {
+	user := common.CurrentUser(r)
+	if user != nil && user.Role == common.RoleCustomer {
+		return user.Name
+	}
+	if id := r.FormValue("customer"); len(id) > 0 {
+		return id
+	}
+	return defaultCustomerID
+}
+
+// isCustomerOrder returns true if the order was placed by the customer,
+// who is named by the ID of the order workflow, see eats.NewOrderID.
+func isCustomerOrder(orderID string, customerID string) bool {
+	prefix := eats.OrderIDPrefix(customerID)
+	if !strings.HasPrefix(orderID, prefix) || len(orderID) == len(prefix) {
+		return false
+	}
+	// the IDs of customer "al" must not match the orders of "al-1"
+	for _, c := range orderID[len(prefix):] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// canAccessOrder returns false if the user is a customer who did not
+// place the order, other roles are authorized by the service policy.
+func (h *EatsService) canAccessOrder(r *http.Request, orderID string) bool {
+	user := common.CurrentUser(r)
+	if user == nil || user.Role != common.RoleCustomer {
+		return user != nil
+	}
+	return isCustomerOrder(orderID, user.Name)
+}
+
+// authorizeOrder writes a not found error, so that customers cannot tell
+// which orders exist, and returns false if the user cannot access the order.
+func (h *EatsService) authorizeOrder(w http.ResponseWriter, r *http.Request, orderID string) bool {
+	if !h.canAccessOrder(r, orderID) {
+		http.Error(w, "Order not found: "+orderID, http.StatusNotFound)
+		return false
+	}
+	return true
+}
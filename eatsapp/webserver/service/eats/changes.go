@@ -0,0 +1,167 @@
+package eats
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/eats"
+)
+
+type (
+	// ChangesRequest models a request asking the customer to confirm
+	// an order that the restaurant accepted with modifications.
+	ChangesRequest struct {
+		eats.OrderChanges
+		WorkflowID string
+		RunID      string
+	}
+
+	// ItemChange models an item rejected or substituted by the restaurant.
+	ItemChange struct {
+		Item       *common.Item
+		Substitute *common.Item
+	}
+
+	// ChangesPage models the confirmation form on the order status page.
+	ChangesPage struct {
+		*ChangesRequest
+		Changes []*ItemChange
+	}
+
+	// ChangesService implements the handlers for requests
+	// sent to the eats order changes http service
+	ChangesService struct {
+		eats *EatsService
+	}
+)
+
+const (
+	changesTable = "eats-changes"
+)
+
+// NewChangesService returns a new ChangesService instance
+func NewChangesService(eats *EatsService) *ChangesService {
+	return &ChangesService{
+		eats: eats,
+	}
+}
+
+func (h *ChangesService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "POST":
+		h.addRequest(w, r)
+	case "PATCH":
+		h.respond(w, r)
+	default:
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+}
+
+// addRequest records the changes made by the restaurant to the order
+// passed as the "id" param and sends them to the eats order workflow.
+func (h *ChangesService) addRequest(w http.ResponseWriter, r *http.Request) {
+	req := ChangesRequest{
+		WorkflowID: r.URL.Query().Get("id"),
+		RunID:      r.URL.Query().Get("run_id"),
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req.OrderChanges); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	req.OrderID = req.WorkflowID
+
+	err := store.PutJSON(h.eats.store, changesTable, req.OrderID, &req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = h.eats.client.SignalWorkflow(req.WorkflowID, req.RunID, eats.OrderChangesSignalName, req.OrderChanges)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%+v", req)
+}
+
+// respond sends the confirmation ("accept") or the cancellation
+// ("decline") of the modified order to the eats order workflow.
+func (h *ChangesService) respond(w http.ResponseWriter, r *http.Request) {
+	orderID := r.URL.Query().Get("id")
+	if !h.eats.authorizeOrder(w, r, orderID) {
+		return
+	}
+	req, err := h.eats.getChanges(orderID)
+	if err == store.ErrNotFound {
+		http.Error(w, "Order not found: "+orderID, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var accepted bool
+	switch action := r.URL.Query().Get("action"); action {
+	case "accept":
+		accepted = true
+	case "decline":
+		accepted = false
+	default:
+		http.Error(w, "Unknown update action: "+action, http.StatusUnprocessableEntity)
+		return
+	}
+
+	err = h.eats.client.SignalWorkflow(req.WorkflowID, req.RunID, eats.ConfirmChangesSignalName, accepted)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.eats.store.Delete(changesTable, orderID)
+	fmt.Fprintf(w, "%+v", accepted)
+}
+
+// getChanges returns the order changes awaiting confirmation.
+func (h *EatsService) getChanges(orderID string) (*ChangesRequest, error) {
+	var req ChangesRequest
+	err := store.GetJSON(h.store, changesTable, orderID, &req)
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// changesPage returns the confirmation form for the order, or nil if
+// the order is not waiting for the customer to confirm any changes.
+func (h *EatsService) changesPage(orderID string) *ChangesPage {
+	req, err := h.getChanges(orderID)
+	if err != nil {
+		return nil
+	}
+
+	page := &ChangesPage{
+		ChangesRequest: req,
+	}
+	for _, id := range req.Rejected {
+		if item, err := h.menu.GetItemByID(id); err == nil {
+			page.Changes = append(page.Changes, &ItemChange{Item: item})
+		}
+	}
+	for id, substituteID := range req.Substitutions {
+		item, err := h.menu.GetItemByID(id)
+		if err != nil {
+			continue
+		}
+		substitute, err := h.menu.GetItemByID(substituteID)
+		if err != nil {
+			continue
+		}
+		page.Changes = append(page.Changes, &ItemChange{Item: item, Substitute: substitute})
+	}
+	return page
+}
@@ -0,0 +1,118 @@
+package eats
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+	courierwf "github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/courier"
+)
+
+type (
+	// DeliveryContact models a request from the courier asking
+	// the customer to respond to a failed delivery attempt.
+	DeliveryContact struct {
+		OrderID    string
+		Reason     string
+		WorkflowID string
+		RunID      string
+	}
+
+	// ContactService implements the handlers for requests sent
+	// to the eats delivery contact http service
+	ContactService struct {
+		eats *EatsService
+	}
+)
+
+const (
+	contactsTable = "eats-delivery-contacts"
+)
+
+// NewContactService returns a new ContactService instance
+func NewContactService(eats *EatsService) *ContactService {
+	return &ContactService{
+		eats: eats,
+	}
+}
+
+func (h *ContactService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "POST":
+		h.addContact(w, r)
+	case "PATCH":
+		h.respond(w, r)
+	default:
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+}
+
+// addContact records the contact request sent by the courier workflow.
+func (h *ContactService) addContact(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	contact := DeliveryContact{
+		OrderID:    r.Form.Get("id"),
+		Reason:     r.Form.Get("reason"),
+		WorkflowID: r.Form.Get("workflow_id"),
+		RunID:      r.Form.Get("run_id"),
+	}
+	err = store.PutJSON(h.eats.store, contactsTable, contact.OrderID, &contact)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%+v", contact)
+}
+
+// respond sends the customer response to the courier workflow.
+func (h *ContactService) respond(w http.ResponseWriter, r *http.Request) {
+	orderID := r.URL.Query().Get("id")
+	if !h.eats.authorizeOrder(w, r, orderID) {
+		return
+	}
+	contact, err := h.eats.getContact(orderID)
+	if err == store.ErrNotFound {
+		http.Error(w, "Order not found: "+orderID, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var response string
+	switch action := r.URL.Query().Get("action"); action {
+	case "retry":
+		response = courierwf.CRRetry
+	case "return":
+		response = courierwf.CRReturn
+	default:
+		http.Error(w, "Unknown update action: "+action, http.StatusUnprocessableEntity)
+		return
+	}
+
+	err = h.eats.client.SignalWorkflow(contact.WorkflowID, contact.RunID, courierwf.CustomerResponseSignalName, response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.eats.store.Delete(contactsTable, orderID)
+	fmt.Fprintf(w, "%+v", contact)
+}
+
+// getContact returns the pending delivery contact request for the order.
+func (h *EatsService) getContact(orderID string) (*DeliveryContact, error) {
+	var contact DeliveryContact
+	err := store.GetJSON(h.store, contactsTable, orderID, &contact)
+	if err != nil {
+		return nil, err
+	}
+	return &contact, nil
+}
@@ -1,12 +1,15 @@
 package eats
 
 import (
-	// "github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/eats"
 	"fmt"
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/eats"
 	"go.uber.org/cadence"
+	"go.uber.org/zap"
 	"net/http"
 	"strings"
-	// "time"
+	"time"
 )
 
 // create creates a new eats order
@@ -25,8 +28,56 @@ func (h *EatsService) create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	execution, err := h.startOrderWorkflow(items)
+	if unavailable := h.menu.Unavailable(items); len(unavailable) > 0 {
+		http.Error(w, "Items not available: "+h.itemNames(unavailable), http.StatusUnprocessableEntity)
+		return
+	}
+
+	// orders placed while the restaurant is closed are either rejected
+	// or scheduled by the restaurant for its next opening
+	if now := time.Now(); !h.hours.IsOpen(now) {
+		opensAt := h.hours.NextOpening(now)
+		if !h.hours.Schedule || opensAt.IsZero() {
+			msg := "The restaurant is closed"
+			if !opensAt.IsZero() {
+				msg += ", it opens " + opensAt.Format("Mon Jan 2 15:04 MST")
+			}
+			http.Error(w, msg, http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
+	customerID := orderingCustomer(r)
+
+	address, err := h.deliveryAddress(r, customerID)
+	if err == errAddressNotFound {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	price, err := h.priceOrder(r, customerID, items)
 	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	err = h.reservePoints(customerID, price.PointsRedeemed)
+	if err == errNotEnoughPoints {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	execution, err := h.startOrderWorkflow(customerID, items)
+	if err != nil {
+		h.releasePoints(customerID, price.PointsRedeemed)
 		if strings.HasPrefix(err.Error(), "WorkflowExecutionAlreadyStartedError") {
 			http.Redirect(w, r, "/eats-orders?error=order_exist", http.StatusFound)
 			return
@@ -35,12 +86,32 @@ func (h *EatsService) create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// the price is used to accrue points once the order completes
+	price.OrderID = execution.ID
+	if err := store.PutJSON(h.store, pricesTable, price.OrderID, price); err != nil {
+		common.Logger().Error("Failed to store order price", zap.String("order", price.OrderID), zap.Error(err))
+	}
+	h.recordOrder(&OrderRecord{
+		OrderID:    execution.ID,
+		RunID:      execution.RunID,
+		CustomerID: customerID,
+		Items:      items,
+		Address:    address,
+		PlacedAt:   time.Now(),
+	})
+	if price.PointsRedeemed > 0 {
+		redemption := eats.PointsChange{OrderID: price.OrderID, Points: price.PointsRedeemed}
+		if err := h.signalLoyalty(customerID, eats.RedeemPointsSignalName, redemption); err != nil {
+			common.Logger().Error("Failed to redeem loyalty points", zap.String("order", price.OrderID), zap.Int("points", price.PointsRedeemed), zap.Error(err))
+		}
+	}
+
 	url := fmt.Sprintf("/eats-orders?id=%s&run_id=%s&page=eats-order-status", execution.ID, execution.RunID)
 	http.Redirect(w, r, url, http.StatusFound)
 }
 
-// startOrderWorkflow starts the eats order workflow
-func (h *EatsService) startOrderWorkflow(items []string) (*cadence.WorkflowExecution, error) {
+// startOrderWorkflow starts the eats order workflow of the customer
+func (h *EatsService) startOrderWorkflow(customerID string, items []string) (*cadence.WorkflowExecution, error) {
 	// THIS IS A PLACEHOLDER IMPLEMENTATION
 	return nil, fmt.Errorf("not implemented")
 }
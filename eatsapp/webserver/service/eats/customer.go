@@ -0,0 +1,235 @@
+package eats
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+)
+
+type (
+	// Customer models the profile of a customer and the addresses
+	// orders are delivered to.
+	Customer struct {
+		ID             string
+		Name           string
+		Email          string
+		Phone          string
+		Addresses      []*Address
+		DefaultAddress string
+	}
+
+	// Address models a saved delivery address of a customer.
+	Address struct {
+		ID           string
+		Label        string
+		Street       string
+		City         string
+		Zip          string
+		Instructions string
+	}
+
+	// CustomerService implements the handlers for requests sent
+	// to the eats customer profile http service
+	CustomerService struct {
+		eats *EatsService
+	}
+
+	// EatsCustomerPage models the data to be displayed on the profile page.
+	EatsCustomerPage struct {
+		*Customer
+		Error string
+	}
+)
+
+const (
+	customersTable = "eats-customers"
+)
+
+var errAddressNotFound = errors.New("delivery address not found")
+
+// NewCustomerService returns a new CustomerService instance
+func NewCustomerService(eats *EatsService) *CustomerService {
+	return &CustomerService{
+		eats: eats,
+	}
+}
+
+func (h *CustomerService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.show(w, r)
+	case "POST":
+		h.update(w, r)
+	default:
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+}
+
+// show renders the profile page, or returns the profile as JSON
+// to the menu page which lists the delivery addresses.
+func (h *CustomerService) show(w http.ResponseWriter, r *http.Request) {
+	customer, err := h.eats.getCustomer(orderingCustomer(r))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		json.NewEncoder(w).Encode(customer)
+		return
+	}
+	common.ViewHandler(w, r, EatsCustomerPage{
+		Customer: customer,
+		Error:    r.URL.Query().Get("error"),
+	})
+}
+
+// update applies the action passed as the "action" param to the profile:
+// profile, add_address, default_address or delete_address.
+func (h *CustomerService) update(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	customerID := orderingCustomer(r)
+	if err := h.eats.createCustomer(customerID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	action := r.URL.Query().Get("action")
+	var customer Customer
+	err = store.UpdateJSON(h.eats.store, customersTable, customerID, &customer, func() error {
+		switch action {
+		case "profile":
+			customer.Name = strings.TrimSpace(r.Form.Get("name"))
+			customer.Email = strings.TrimSpace(r.Form.Get("email"))
+			customer.Phone = strings.TrimSpace(r.Form.Get("phone"))
+		case "add_address":
+			return customer.addAddress(r)
+		case "default_address":
+			if customer.getAddress(r.Form.Get("address_id")) == nil {
+				return errAddressNotFound
+			}
+			customer.DefaultAddress = r.Form.Get("address_id")
+		case "delete_address":
+			return customer.deleteAddress(r.Form.Get("address_id"))
+		default:
+			return fmt.Errorf("unknown profile action: %s", action)
+		}
+		return nil
+	})
+	if err == errAddressNotFound {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Redirect(w, r, "/eats-customer?error="+url.QueryEscape(err.Error()), http.StatusFound)
+		return
+	}
+	http.Redirect(w, r, "/eats-customer", http.StatusFound)
+}
+
+// getCustomer returns the profile of the customer, customers who never
+// saved their profile have an empty one.
+func (h *EatsService) getCustomer(customerID string) (*Customer, error) {
+	customer := Customer{ID: customerID}
+	err := store.GetJSON(h.store, customersTable, customerID, &customer)
+	if err != nil && err != store.ErrNotFound {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// createCustomer stores an empty profile for the customer, unless
+// the customer already saved one.
+func (h *EatsService) createCustomer(customerID string) error {
+	_, err := h.store.Get(customersTable, customerID)
+	if err != store.ErrNotFound {
+		return err
+	}
+	return store.PutJSON(h.store, customersTable, customerID, &Customer{ID: customerID})
+}
+
+// deliveryAddress returns the saved address passed as the "address_id"
+// form value, or the default address of the customer, nil if the
+// customer has not saved any address.
+func (h *EatsService) deliveryAddress(r *http.Request, customerID string) (*Address, error) {
+	customer, err := h.getCustomer(customerID)
+	if err != nil {
+		return nil, err
+	}
+	id := r.Form.Get("address_id")
+	if len(id) == 0 {
+		id = customer.DefaultAddress
+	}
+	if len(id) == 0 {
+		return nil, nil
+	}
+	address := customer.getAddress(id)
+	if address == nil {
+		return nil, errAddressNotFound
+	}
+	return address, nil
+}
+
+// addAddress saves the address from the form, the first
+// address of the customer becomes the default one.
+func (c *Customer) addAddress(r *http.Request) error {
+	address := &Address{
+		ID:           fmt.Sprintf("ADDR-%v", time.Now().UnixNano()),
+		Label:        strings.TrimSpace(r.Form.Get("label")),
+		Street:       strings.TrimSpace(r.Form.Get("street")),
+		City:         strings.TrimSpace(r.Form.Get("city")),
+		Zip:          strings.TrimSpace(r.Form.Get("zip")),
+		Instructions: strings.TrimSpace(r.Form.Get("instructions")),
+	}
+	if len(address.Street) == 0 || len(address.City) == 0 {
+		return errors.New("the street and city of the address are required")
+	}
+	if len(address.Label) == 0 {
+		address.Label = address.Street
+	}
+	c.Addresses = append(c.Addresses, address)
+	if len(c.DefaultAddress) == 0 {
+		c.DefaultAddress = address.ID
+	}
+	return nil
+}
+
+// deleteAddress removes the saved address, the next saved
+// address becomes the default one if needed.
+func (c *Customer) deleteAddress(id string) error {
+	for i, address := range c.Addresses {
+		if address.ID != id {
+			continue
+		}
+		c.Addresses = append(c.Addresses[:i], c.Addresses[i+1:]...)
+		if c.DefaultAddress == id {
+			c.DefaultAddress = ""
+			if len(c.Addresses) > 0 {
+				c.DefaultAddress = c.Addresses[0].ID
+			}
+		}
+		return nil
+	}
+	return errAddressNotFound
+}
+
+func (c *Customer) getAddress(id string) *Address {
+	for _, address := range c.Addresses {
+		if address.ID == id {
+			return address
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package eats
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	s "go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/zap"
+)
+
+type (
+	// WorkflowEvent models the latest event of an eats order workflow
+	// pushed to the order status page.
+	WorkflowEvent struct {
+		EventID   int64
+		EventType string
+		Closed    bool
+	}
+)
+
+// The pinned cadence client cannot long poll a workflow history, so it is
+// polled less and less often while it does not grow, and right away again
+// once the order changes in the store.
+const (
+	historyPollMinInterval = time.Second
+	historyPollMaxInterval = time.Second * 30
+)
+
+// OrderTables lists the tables keyed by order ID, whose changes are
+// published to the topic of the order.
+var OrderTables = []string{
+	common.OrderPricesTable,
+	contactsTable,
+	substitutionsTable,
+	changesTable,
+	ordersTable,
+}
+
+// AuthorizeTopic returns true if the user of the request can access the
+// order of the topic.
+func (h *EatsService) AuthorizeTopic(r *http.Request, topic string) bool {
+	return h.canAccessOrder(r, strings.TrimPrefix(topic, common.OrderTopicPrefix))
+}
+
+// WatchHistory polls the history of the eats order workflow of the topic,
+// run ID passed as the "run_id" param, publishing an event whenever the
+// history grows. It returns once the workflow is closed.
+func (h *EatsService) WatchHistory(ctx context.Context, publish common.PublishFunc, changed <-chan struct{}, topic string, query url.Values) {
+	orderID := strings.TrimPrefix(topic, common.OrderTopicPrefix)
+	runID := query.Get("run_id")
+
+	var seen int
+	interval := historyPollMinInterval
+	for {
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-changed:
+			// the services store the order before calling cadence, so
+			// the history is polled after the call rather than now
+			timer.Stop()
+			interval = historyPollMinInterval
+			continue
+		case <-timer.C:
+		}
+
+		if interval *= 2; interval > historyPollMaxInterval {
+			interval = historyPollMaxInterval
+		}
+		history, err := h.client.GetWorkflowHistory(orderID, runID)
+		if err != nil {
+			common.Logger().Error("Failed to read order history", zap.String("order", orderID), zap.Error(err))
+			continue
+		}
+		if len(history.Events) <= seen {
+			continue
+		}
+		seen = len(history.Events)
+		interval = historyPollMinInterval
+
+		last := history.Events[seen-1]
+		event := &WorkflowEvent{
+			EventID:   *last.EventId,
+			EventType: last.EventType.String(),
+			Closed:    isCloseEvent(*last.EventType),
+		}
+		publish(common.EventWorkflow, event)
+		if event.Closed {
+			return
+		}
+	}
+}
+
+func isCloseEvent(eventType s.EventType) bool {
+	switch eventType {
+	case s.EventType_WorkflowExecutionCompleted,
+		s.EventType_WorkflowExecutionFailed,
+		s.EventType_WorkflowExecutionTimedOut,
+		s.EventType_WorkflowExecutionCanceled,
+		s.EventType_WorkflowExecutionTerminated,
+		s.EventType_WorkflowExecutionContinuedAsNew:
+		return true
+	}
+	return false
+}
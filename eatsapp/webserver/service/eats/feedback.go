@@ -0,0 +1,87 @@
+package eats
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/eats"
+)
+
+type (
+	// FeedbackService implements the handlers for requests
+	// sent to the eats order feedback http service
+	FeedbackService struct {
+		eats *EatsService
+	}
+)
+
+// NewFeedbackService returns a new FeedbackService instance
+func NewFeedbackService(eats *EatsService) *FeedbackService {
+	return &FeedbackService{
+		eats: eats,
+	}
+}
+
+func (h *FeedbackService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "PATCH":
+		h.rate(w, r)
+	default:
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+}
+
+// rate sends the customer ratings to the eats order workflow.
+func (h *FeedbackService) rate(w http.ResponseWriter, r *http.Request) {
+	orderID := r.URL.Query().Get("id")
+	if len(orderID) == 0 {
+		http.Error(w, "No order specified!", http.StatusUnprocessableEntity)
+		return
+	}
+	if !h.eats.authorizeOrder(w, r, orderID) {
+		return
+	}
+
+	var feedback eats.Feedback
+	var err error
+	if v := r.URL.Query().Get("courier"); len(v) > 0 {
+		if feedback.CourierRating, err = service.ParseRating(v); err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+	}
+	if v := r.URL.Query().Get("restaurant"); len(v) > 0 {
+		if feedback.RestaurantRating, err = service.ParseRating(v); err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
+	err = h.eats.client.SignalWorkflow(orderID, "", eats.FeedbackSignalName, feedback)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%+v", feedback)
+}
+
+// awaitingFeedback returns true once the order was charged and
+// the workflow is waiting for the customer to rate it.
+func awaitingFeedback(group *TaskGroup) bool {
+	if group.Status != "r" {
+		return false
+	}
+
+	charged := false
+	for _, task := range group.Tasks {
+		switch task.Name {
+		case "ChargeOrderActivity":
+			charged = task.Status == "c"
+		case "RecordFeedbackActivity":
+			return false
+		}
+	}
+	return charged
+}
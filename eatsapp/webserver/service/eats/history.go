@@ -0,0 +1,230 @@
+package eats
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+	s "go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/zap"
+)
+
+type (
+	// OrderRecord models an order placed by a customer, kept so that
+	// the customer can see what was ordered and order it again.
+	OrderRecord struct {
+		OrderID    string
+		RunID      string
+		CustomerID string
+		Items      []string
+		Address    *Address
+		PlacedAt   time.Time
+	}
+
+	// CustomerOrder models an order listed on the order history page.
+	CustomerOrder struct {
+		ID         string
+		RunID      string
+		Status     string
+		StartedAt  time.Time
+		ClosedAt   time.Time
+		Items      string
+		Address    *Address
+		Total      float32
+		CanReorder bool
+	}
+
+	// HistoryService implements the handlers for requests sent
+	// to the eats order history http service
+	HistoryService struct {
+		eats *EatsService
+	}
+
+	// EatsOrderHistoryPage models the data to be displayed on the
+	// order history page of a customer.
+	EatsOrderHistoryPage struct {
+		Open         []*CustomerOrder
+		Closed       []*CustomerOrder
+		NextPageURL  string
+		FirstPageURL string
+	}
+)
+
+const (
+	ordersTable = "eats-customer-orders"
+
+	// historyPeriod is how far back the orders are listed.
+	historyPeriod   = time.Hour * 24 * 30
+	historyPageSize = 20
+)
+
+// NewHistoryService returns a new HistoryService instance
+func NewHistoryService(eats *EatsService) *HistoryService {
+	return &HistoryService{
+		eats: eats,
+	}
+}
+
+func (h *HistoryService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.show(w, r)
+	case "POST":
+		h.reorder(w, r)
+	default:
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+}
+
+// show lists the open orders of the customer and a page of the closed
+// orders, the closed orders page being passed as the "next_page" param.
+func (h *HistoryService) show(w http.ResponseWriter, r *http.Request) {
+	customerID := orderingCustomer(r)
+	open := historyFilter(OrderTabOpen, "")
+	closed := historyFilter(OrderTabClosed, r.URL.Query().Get("next_page"))
+	if _, err := closed.cursor(); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	openOrders, _, err := h.eats.listOrderPage(r, open)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	closedOrders, nextPage, err := h.eats.listOrderPage(r, closed)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	page := EatsOrderHistoryPage{
+		Open:   h.eats.customerOrders(customerID, openOrders),
+		Closed: h.eats.customerOrders(customerID, closedOrders),
+	}
+	params := url.Values{}
+	if customer := r.URL.Query().Get("customer"); len(customer) > 0 {
+		params.Set("customer", customer)
+	}
+	if len(nextPage) > 0 {
+		params.Set("next_page", nextPage)
+		page.NextPageURL = "/eats-history?" + params.Encode()
+		params.Del("next_page")
+	}
+	if len(closed.PageToken) > 0 {
+		page.FirstPageURL = "/eats-history?" + params.Encode()
+	}
+	common.ViewHandler(w, r, page)
+}
+
+// historyFilter returns the filter listing the eats orders of the tab
+// started over the history period, from the page token.
+func historyFilter(tab string, pageToken string) *OrderListFilter {
+	now := time.Now()
+	return &OrderListFilter{
+		Tab:          tab,
+		From:         now.Add(-historyPeriod),
+		To:           now.Add(time.Minute),
+		WorkflowType: "eats",
+		Sort:         SortNewest,
+		PageSize:     historyPageSize,
+		PageToken:    pageToken,
+	}
+}
+
+// reorder places a new order with the items of the order passed as the
+// "id" param, delivered to the same address if it is still saved.
+func (h *HistoryService) reorder(w http.ResponseWriter, r *http.Request) {
+	if action := r.URL.Query().Get("action"); action != "reorder" {
+		http.Error(w, "Unknown history action: "+action, http.StatusUnprocessableEntity)
+		return
+	}
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	orderID := r.URL.Query().Get("id")
+	if !h.eats.authorizeOrder(w, r, orderID) {
+		return
+	}
+	record, err := h.eats.getOrderRecord(orderID)
+	if err == store.ErrNotFound {
+		http.Error(w, "Order cannot be placed again: "+orderID, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	r.Form["item-id"] = record.Items
+	r.Form.Set("customer", record.CustomerID)
+	r.Form.Del("address_id")
+	if record.Address != nil {
+		customer, err := h.eats.getCustomer(record.CustomerID)
+		if err == nil && customer.getAddress(record.Address.ID) != nil {
+			r.Form.Set("address_id", record.Address.ID)
+		}
+	}
+	h.eats.create(w, r)
+}
+
+// recordOrder stores the order placed by the customer.
+func (h *EatsService) recordOrder(record *OrderRecord) {
+	if err := store.PutJSON(h.store, ordersTable, record.OrderID, record); err != nil {
+		common.Logger().Error("Failed to record customer order", zap.String("order", record.OrderID), zap.Error(err))
+	}
+}
+
+func (h *EatsService) getOrderRecord(orderID string) (*OrderRecord, error) {
+	var record OrderRecord
+	if err := store.GetJSON(h.store, ordersTable, orderID, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// customerOrders returns the orders of the customer among the workflow
+// executions, most recent first.
+func (h *EatsService) customerOrders(customerID string, executions []*s.WorkflowExecutionInfo) []*CustomerOrder {
+	orders := make([]*CustomerOrder, 0, len(executions))
+	for _, e := range executions {
+		orderID := *e.Execution.WorkflowId
+		if !isCustomerOrder(orderID, customerID) {
+			continue
+		}
+
+		order := &CustomerOrder{
+			ID:        orderID,
+			RunID:     *e.Execution.RunId,
+			Status:    "OPEN",
+			StartedAt: time.Unix(0, *e.StartTime),
+		}
+		if e.CloseStatus != nil {
+			order.Status = e.CloseStatus.String()
+		}
+		if e.CloseTime != nil {
+			order.ClosedAt = time.Unix(0, *e.CloseTime)
+		}
+		if record, err := h.getOrderRecord(orderID); err == nil {
+			order.Items = h.itemNames(record.Items)
+			order.Address = record.Address
+			order.CanReorder = len(record.Items) > 0
+		}
+		var price common.OrderPrice
+		if err := store.GetJSON(h.store, pricesTable, orderID, &price); err == nil {
+			order.Total = price.Total
+		}
+		orders = append(orders, order)
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].StartedAt.After(orders[j].StartedAt)
+	})
+	return orders
+}
@@ -1,7 +1,9 @@
 package eats
 
 import (
+	config "github.com/venkat1109/cadence-codelab/common"
 	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
 	"go.uber.org/cadence"
 	s "go.uber.org/cadence/.gen/go/shared"
 	"net/http"
@@ -11,15 +13,33 @@ type (
 	// EatsService implements the handler for requests sent
 	// to the Eats http service
 	EatsService struct {
-		menu   *common.Menu
-		client cadence.Client
+		menu    *common.Menu
+		hours   *common.Hours
+		loyalty config.LoyaltyConfig
+		client  cadence.Client
+		store   store.Store
 	}
 
 	// EatsOrderListPage models the data to be displayed in response to
 	// GET requests to the Eats service.
 	EatsOrderListPage struct {
 		ShowOrderExistError bool
-		Orders              *s.ListOpenWorkflowExecutionsResponse
+		Orders              []*s.WorkflowExecutionInfo
+		Filter              *OrderListFilter
+		Tabs                []string
+		Statuses            []string
+		WorkflowTypes       []string
+		NextPageURL         string
+		FirstPageURL        string
+	}
+
+	// EatsOrderStatusPage models the data to be displayed for a single order.
+	EatsOrderStatusPage struct {
+		*TaskGroup
+		Contact          *DeliveryContact
+		Substitution     *SubstitutionPage
+		Changes          *ChangesPage
+		AwaitingFeedback bool
 	}
 )
 
@@ -28,10 +48,13 @@ const (
 )
 
 // NewService returns a new EatsService instance
-func NewService(c cadence.Client, menu *common.Menu) *EatsService {
+func NewService(c cadence.Client, menu *common.Menu, hours *common.Hours, loyalty config.LoyaltyConfig, s store.Store) *EatsService {
 	return &EatsService{
-		client: c,
-		menu:   menu,
+		client:  c,
+		menu:    menu,
+		hours:   hours,
+		loyalty: loyalty,
+		store:   s,
 	}
 }
 
@@ -0,0 +1,351 @@
+package eats
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	courierwf "github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/courier"
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/eats"
+	restaurantwf "github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/restaurant"
+	s "go.uber.org/cadence/.gen/go/shared"
+)
+
+type (
+	// OrderListFilter models the params of the order list page, which
+	// map onto the ListOpenWorkflow and ListClosedWorkflow requests.
+	OrderListFilter struct {
+		Tab          string // open, closed or all
+		From         time.Time
+		To           time.Time
+		Status       string // close status of the closed workflows
+		WorkflowType string // eats, restaurant or courier
+		Sort         string // newest or oldest first, within the page
+		PageSize     int
+		PageToken    string
+	}
+
+	// orderListCursor tells which list the next page is read from,
+	// the "all" tab reads the open workflows before the closed ones.
+	orderListCursor struct {
+		closed bool
+		token  []byte
+	}
+)
+
+// Values of the order list params.
+const (
+	OrderTabOpen   = "open"
+	OrderTabClosed = "closed"
+	OrderTabAll    = "all"
+
+	SortNewest = "newest"
+	SortOldest = "oldest"
+
+	defaultOrderListPeriod = time.Hour * 10
+	defaultOrderPageSize   = 20
+	maxOrderPageSize       = 100
+
+	// maxOrderListReads bounds the requests sent to cadence for a page,
+	// a page left short by the orders of other customers links to the
+	// next one rather than reading the whole time range.
+	maxOrderListReads = 5
+
+	// orderTimeLayout is the format of the from and to params,
+	// as sent by datetime-local inputs.
+	orderTimeLayout = "2006-01-02T15:04"
+)
+
+// OrderTabs lists the tabs of the order list page.
+var OrderTabs = []string{OrderTabOpen, OrderTabClosed, OrderTabAll}
+
+// CloseStatuses maps the status filter values to the workflow close statuses.
+var CloseStatuses = map[string]s.WorkflowExecutionCloseStatus{
+	"COMPLETED":        s.WorkflowExecutionCloseStatus_COMPLETED,
+	"FAILED":           s.WorkflowExecutionCloseStatus_FAILED,
+	"CANCELED":         s.WorkflowExecutionCloseStatus_CANCELED,
+	"TERMINATED":       s.WorkflowExecutionCloseStatus_TERMINATED,
+	"CONTINUED_AS_NEW": s.WorkflowExecutionCloseStatus_CONTINUED_AS_NEW,
+	"TIMED_OUT":        s.WorkflowExecutionCloseStatus_TIMED_OUT,
+}
+
+// workflowTypes maps the workflow type filter values to the workflow functions.
+var workflowTypes = map[string]interface{}{
+	"eats":       eats.OrderWorkflow,
+	"restaurant": restaurantwf.OrderWorkflow,
+	"courier":    courierwf.OrderWorkflow,
+}
+
+// closeStatusNames returns the values of the status filter.
+func closeStatusNames() []string {
+	names := make([]string, 0, len(CloseStatuses))
+	for name := range CloseStatuses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// workflowTypeNames returns the values of the workflow type filter.
+func workflowTypeNames() []string {
+	names := make([]string, 0, len(workflowTypes))
+	for name := range workflowTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseOrderListFilter returns the filter of the request params,
+// which lists the open eats orders of the past ten hours by default.
+// A status filter only applies to closed workflows, the "all" tab is
+// then limited to the closed ones.
+func parseOrderListFilter(r *http.Request) (*OrderListFilter, error) {
+	query := r.URL.Query()
+	now := time.Now()
+	filter := &OrderListFilter{
+		Tab:          query.Get("tab"),
+		From:         now.Add(-defaultOrderListPeriod),
+		To:           now.Add(time.Minute),
+		Status:       strings.ToUpper(query.Get("status")),
+		WorkflowType: query.Get("type"),
+		Sort:         query.Get("sort"),
+		PageSize:     defaultOrderPageSize,
+		PageToken:    query.Get("next_page"),
+	}
+
+	switch filter.Tab {
+	case "":
+		filter.Tab = OrderTabOpen
+	case OrderTabOpen, OrderTabClosed, OrderTabAll:
+	default:
+		return nil, fmt.Errorf("unknown order tab: %s", filter.Tab)
+	}
+	if len(filter.Status) > 0 {
+		if _, ok := CloseStatuses[filter.Status]; !ok {
+			return nil, fmt.Errorf("unknown order status: %s", filter.Status)
+		}
+		if filter.Tab == OrderTabOpen {
+			return nil, fmt.Errorf("open orders have no status: %s", filter.Status)
+		}
+	}
+	if len(filter.WorkflowType) == 0 {
+		filter.WorkflowType = "eats"
+	}
+	if _, ok := workflowTypes[filter.WorkflowType]; !ok {
+		return nil, fmt.Errorf("unknown workflow type: %s", filter.WorkflowType)
+	}
+	switch filter.Sort {
+	case "":
+		filter.Sort = SortNewest
+	case SortNewest, SortOldest:
+	default:
+		return nil, fmt.Errorf("unknown sort order: %s", filter.Sort)
+	}
+
+	var err error
+	if v := query.Get("from"); len(v) > 0 {
+		if filter.From, err = time.ParseInLocation(orderTimeLayout, v, time.Local); err != nil {
+			return nil, fmt.Errorf("invalid from time %q", v)
+		}
+	}
+	if v := query.Get("to"); len(v) > 0 {
+		if filter.To, err = time.ParseInLocation(orderTimeLayout, v, time.Local); err != nil {
+			return nil, fmt.Errorf("invalid to time %q", v)
+		}
+	}
+	if !filter.From.Before(filter.To) {
+		return nil, fmt.Errorf("the from time must be before the to time")
+	}
+	if v := query.Get("page_size"); len(v) > 0 {
+		size, err := strconv.Atoi(v)
+		if err != nil || size <= 0 || size > maxOrderPageSize {
+			return nil, fmt.Errorf("invalid page size %q, expected 1 to %d", v, maxOrderPageSize)
+		}
+		filter.PageSize = size
+	}
+	if _, err := filter.cursor(); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// Params returns the params of the filter, without the page token.
+func (f *OrderListFilter) Params() url.Values {
+	params := url.Values{
+		"tab":       {f.Tab},
+		"from":      {f.From.Format(orderTimeLayout)},
+		"to":        {f.To.Format(orderTimeLayout)},
+		"type":      {f.WorkflowType},
+		"sort":      {f.Sort},
+		"page_size": {strconv.Itoa(f.PageSize)},
+	}
+	if len(f.Status) > 0 {
+		params.Set("status", f.Status)
+	}
+	return params
+}
+
+// URL returns the order list URL of the filter, with the page token.
+func (f *OrderListFilter) URL(pageToken string) string {
+	params := f.Params()
+	if len(pageToken) > 0 {
+		params.Set("next_page", pageToken)
+	}
+	return "/eats-orders?" + params.Encode()
+}
+
+// TabURL returns the URL of the first page of the tab.
+func (f *OrderListFilter) TabURL(tab string) string {
+	params := f.Params()
+	params.Set("tab", tab)
+	if tab == OrderTabOpen {
+		params.Del("status")
+	}
+	return "/eats-orders?" + params.Encode()
+}
+
+// listOrderPage returns a page of the workflows matching the filter,
+// keeping the ones accessible to the user, and the token of the next
+// page, empty on the last page. Pages are read until the page is full,
+// up to maxOrderListReads, so that the orders of other customers do not
+// leave it short.
+//
+// Cadence lists the most recent workflows first, the "oldest" sort order
+// only reorders the workflows of the page, not the pages.
+func (h *EatsService) listOrderPage(r *http.Request, filter *OrderListFilter) ([]*s.WorkflowExecutionInfo, string, error) {
+	cursor, err := filter.cursor()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var executions []*s.WorkflowExecutionInfo
+	for reads := 0; len(executions) < filter.PageSize && cursor != nil && reads < maxOrderListReads; reads++ {
+		remaining := int32(filter.PageSize - len(executions))
+		var page []*s.WorkflowExecutionInfo
+		var next []byte
+		if cursor.closed {
+			rsp, err := h.client.ListClosedWorkflow(filter.closedRequest(remaining, cursor.token))
+			if err != nil {
+				return nil, "", err
+			}
+			page, next = rsp.Executions, rsp.NextPageToken
+		} else {
+			rsp, err := h.client.ListOpenWorkflow(filter.openRequest(remaining, cursor.token))
+			if err != nil {
+				return nil, "", err
+			}
+			page, next = rsp.Executions, rsp.NextPageToken
+		}
+
+		for _, e := range page {
+			if filter.matchesType(e) && h.canAccessOrder(r, *e.Execution.WorkflowId) {
+				executions = append(executions, e)
+			}
+		}
+		cursor = filter.nextCursor(cursor, next)
+	}
+
+	if filter.Sort == SortOldest {
+		sort.SliceStable(executions, func(i, j int) bool {
+			return *executions[i].StartTime < *executions[j].StartTime
+		})
+	}
+	return executions, cursor.String(), nil
+}
+
+// cursor returns the cursor of the page token.
+func (f *OrderListFilter) cursor() (*orderListCursor, error) {
+	if len(f.PageToken) == 0 {
+		closed := f.Tab == OrderTabClosed || len(f.Status) > 0
+		return &orderListCursor{closed: closed}, nil
+	}
+	parts := strings.SplitN(f.PageToken, ":", 2)
+	if len(parts) != 2 || (parts[0] != OrderTabOpen && parts[0] != OrderTabClosed) {
+		return nil, fmt.Errorf("invalid page token")
+	}
+	token, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid page token")
+	}
+	return &orderListCursor{closed: parts[0] == OrderTabClosed, token: token}, nil
+}
+
+// nextCursor returns the cursor following the page, nil after the last page.
+func (f *OrderListFilter) nextCursor(cursor *orderListCursor, token []byte) *orderListCursor {
+	if len(token) > 0 {
+		return &orderListCursor{closed: cursor.closed, token: token}
+	}
+	if !cursor.closed && f.Tab == OrderTabAll {
+		return &orderListCursor{closed: true}
+	}
+	return nil
+}
+
+// String returns the page token of the cursor, empty for a nil cursor.
+func (c *orderListCursor) String() string {
+	if c == nil {
+		return ""
+	}
+	list := OrderTabOpen
+	if c.closed {
+		list = OrderTabClosed
+	}
+	return list + ":" + base64.RawURLEncoding.EncodeToString(c.token)
+}
+
+func (f *OrderListFilter) openRequest(pageSize int32, token []byte) *s.ListOpenWorkflowExecutionsRequest {
+	return &s.ListOpenWorkflowExecutionsRequest{
+		MaximumPageSize: &pageSize,
+		NextPageToken:   token,
+		StartTimeFilter: f.startTimeFilter(),
+		TypeFilter:      f.typeFilter(),
+	}
+}
+
+// closedRequest filters the closed workflows by status if the filter has
+// one, and otherwise by type. Cadence accepts a single filter besides the
+// start time, matchesType filters the type of the listed workflows.
+func (f *OrderListFilter) closedRequest(pageSize int32, token []byte) *s.ListClosedWorkflowExecutionsRequest {
+	req := &s.ListClosedWorkflowExecutionsRequest{
+		MaximumPageSize: &pageSize,
+		NextPageToken:   token,
+		StartTimeFilter: f.startTimeFilter(),
+	}
+	if status, ok := CloseStatuses[f.Status]; ok {
+		req.StatusFilter = &status
+	} else {
+		req.TypeFilter = f.typeFilter()
+	}
+	return req
+}
+
+// matchesType returns true if the workflow is of the type of the filter,
+// for the workflows listed without a type filter.
+func (f *OrderListFilter) matchesType(e *s.WorkflowExecutionInfo) bool {
+	if e.Type == nil || e.Type.Name == nil {
+		return true
+	}
+	return *e.Type.Name == *f.typeFilter().Name
+}
+
+func (f *OrderListFilter) startTimeFilter() *s.StartTimeFilter {
+	earliest := f.From.UnixNano()
+	latest := f.To.UnixNano()
+	return &s.StartTimeFilter{
+		EarliestTime: &earliest,
+		LatestTime:   &latest,
+	}
+}
+
+func (f *OrderListFilter) typeFilter() *s.WorkflowTypeFilter {
+	name := getWorkflowName(workflowTypes[f.WorkflowType])
+	return &s.WorkflowTypeFilter{
+		Name: &name,
+	}
+}
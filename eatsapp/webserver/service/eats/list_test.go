@@ -0,0 +1,71 @@
+package eats
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestOrderListCursor(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter OrderListFilter
+		closed bool
+		token  []byte
+		err    bool
+	}{
+		{name: "first open page", filter: OrderListFilter{Tab: OrderTabOpen}},
+		{name: "first closed page", filter: OrderListFilter{Tab: OrderTabClosed}, closed: true},
+		{name: "status filter lists closed orders", filter: OrderListFilter{Tab: OrderTabAll, Status: "FAILED"}, closed: true},
+		{name: "open page token", filter: OrderListFilter{PageToken: "open:AQL_"}, token: []byte{1, 2, 255}},
+		{name: "closed page token", filter: OrderListFilter{PageToken: "closed:AQL_"}, closed: true, token: []byte{1, 2, 255}},
+		{name: "first closed page of the all tab", filter: OrderListFilter{PageToken: "closed:"}, closed: true},
+		{name: "unknown list", filter: OrderListFilter{PageToken: "all:AQL_"}, err: true},
+		{name: "missing list", filter: OrderListFilter{PageToken: "AQL_"}, err: true},
+		{name: "padded token", filter: OrderListFilter{PageToken: "open:AQL_AA=="}, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := tt.filter.cursor()
+			if (err != nil) != tt.err {
+				t.Fatalf("cursor() error = %v, want error %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if cursor.closed != tt.closed || !bytes.Equal(cursor.token, tt.token) {
+				t.Errorf("cursor() = %+v, want closed %v and token %v", cursor, tt.closed, tt.token)
+			}
+
+			// the page token of the cursor reads the same cursor back
+			filter := OrderListFilter{PageToken: cursor.String()}
+			again, err := filter.cursor()
+			if err != nil || again.closed != cursor.closed || !bytes.Equal(again.token, cursor.token) {
+				t.Errorf("cursor of %q = %+v, %v, want %+v", cursor.String(), again, err, cursor)
+			}
+		})
+	}
+}
+
+func TestOrderListNextCursor(t *testing.T) {
+	tests := []struct {
+		name   string
+		tab    string
+		cursor orderListCursor
+		token  []byte
+		want   string
+	}{
+		{"more open orders", OrderTabOpen, orderListCursor{}, []byte{1}, "open:AQ"},
+		{"last open page", OrderTabOpen, orderListCursor{}, nil, ""},
+		{"all tab moves on to the closed orders", OrderTabAll, orderListCursor{}, nil, "closed:"},
+		{"more closed orders", OrderTabAll, orderListCursor{closed: true}, []byte{1}, "closed:AQ"},
+		{"last closed page", OrderTabAll, orderListCursor{closed: true}, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := OrderListFilter{Tab: tt.tab}
+			if got := filter.nextCursor(&tt.cursor, tt.token).String(); got != tt.want {
+				t.Errorf("nextCursor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package eats
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/message"
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/eats"
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+)
+
+type (
+	// LoyaltyService implements the handlers for requests sent to the
+	// eats loyalty http service, which accrues points on completed orders
+	// and keeps a copy of the balance held by the loyalty workflow.
+	LoyaltyService struct {
+		eats *EatsService
+	}
+)
+
+const (
+	loyaltyTable = "eats-loyalty"
+
+	// defaultCustomerID is used for orders placed without a customer.
+	defaultCustomerID = "joe"
+)
+
+var errNotEnoughPoints = errors.New("not enough loyalty points")
+
+// NewLoyaltyService returns a new LoyaltyService instance
+func NewLoyaltyService(eats *EatsService) *LoyaltyService {
+	return &LoyaltyService{
+		eats: eats,
+	}
+}
+
+func (h *LoyaltyService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.showBalance(w, r)
+	case "POST":
+		h.accrue(w, r)
+	case "PATCH":
+		h.updateBalance(w, r)
+	default:
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+}
+
+// showBalance returns the points balance of the customer as JSON.
+func (h *LoyaltyService) showBalance(w http.ResponseWriter, r *http.Request) {
+	balance, err := h.eats.loyaltyBalance(orderingCustomer(r))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(balance)
+}
+
+// accrue credits the customer who placed the completed order passed as
+// the "id" param with points for the amount paid, starting the loyalty
+// workflow of the customer if it is not running.
+func (h *LoyaltyService) accrue(w http.ResponseWriter, r *http.Request) {
+	if action := r.URL.Query().Get("action"); action != "accrue" {
+		http.Error(w, "Unknown loyalty action: "+action, http.StatusUnprocessableEntity)
+		return
+	}
+
+	orderID := r.URL.Query().Get("id")
+	var price common.OrderPrice
+	err := store.GetJSON(h.eats.store, pricesTable, orderID, &price)
+	if err == store.ErrNotFound {
+		http.Error(w, "Order not found: "+orderID, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	change := eats.PointsChange{
+		OrderID: orderID,
+		Points:  int(price.Total * h.eats.loyalty.PointsPerDollar),
+	}
+	err = h.eats.signalLoyalty(price.CustomerID, eats.AccruePointsSignalName, change)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%+v", change)
+}
+
+// updateBalance stores the balance published by the loyalty workflow.
+func (h *LoyaltyService) updateBalance(w http.ResponseWriter, r *http.Request) {
+	var balance message.LoyaltyBalance
+	if err := json.NewDecoder(r.Body).Decode(&balance); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	balance.CustomerID = r.URL.Query().Get("customer")
+
+	err := store.PutJSON(h.eats.store, loyaltyTable, balance.CustomerID, &balance)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%+v", balance)
+}
+
+// loyaltyBalance returns the points balance of the customer, customers
+// without a balance have not completed any orders yet.
+func (h *EatsService) loyaltyBalance(customerID string) (*message.LoyaltyBalance, error) {
+	balance := message.LoyaltyBalance{CustomerID: customerID}
+	err := store.GetJSON(h.store, loyaltyTable, customerID, &balance)
+	if err != nil && err != store.ErrNotFound {
+		return nil, err
+	}
+	return &balance, nil
+}
+
+// reservePoints takes the points redeemed on a new order out of the
+// balance, before the loyalty workflow is told about the redemption.
+func (h *EatsService) reservePoints(customerID string, points int) error {
+	if points == 0 {
+		return nil
+	}
+	var balance message.LoyaltyBalance
+	err := store.UpdateJSON(h.store, loyaltyTable, customerID, &balance, func() error {
+		if balance.Points < points {
+			return errNotEnoughPoints
+		}
+		balance.Points -= points
+		return nil
+	})
+	if err == store.ErrNotFound {
+		return errNotEnoughPoints
+	}
+	return err
+}
+
+// releasePoints puts back the points reserved for an order that was not placed.
+func (h *EatsService) releasePoints(customerID string, points int) {
+	if points == 0 {
+		return
+	}
+	var balance message.LoyaltyBalance
+	err := store.UpdateJSON(h.store, loyaltyTable, customerID, &balance, func() error {
+		balance.Points += points
+		return nil
+	})
+	if err != nil {
+		common.Logger().Error("Failed to release loyalty points", zap.String("customer", customerID), zap.Int("points", points), zap.Error(err))
+	}
+}
+
+// signalLoyalty sends the points change to the loyalty workflow of the
+// customer, starting the workflow first if it is not running.
+func (h *EatsService) signalLoyalty(customerID string, signalName string, change eats.PointsChange) error {
+	workflowOptions := cadence.StartWorkflowOptions{
+		ID:                              eats.LoyaltyWorkflowID(customerID),
+		TaskList:                        cadenceTaskList,
+		ExecutionStartToCloseTimeout:    eats.LoyaltyExecutionTimeout,
+		DecisionTaskStartToCloseTimeout: time.Minute,
+	}
+	account := &eats.LoyaltyAccount{
+		CustomerID:   customerID,
+		PointsExpiry: h.loyalty.PointsExpiry,
+	}
+
+	_, err := h.client.StartWorkflow(workflowOptions, eats.LoyaltyWorkflow, account)
+	if err != nil && !strings.HasPrefix(err.Error(), "WorkflowExecutionAlreadyStartedError") {
+		return err
+	}
+	return h.client.SignalWorkflow(eats.LoyaltyWorkflowID(customerID), "", signalName, change)
+}
@@ -0,0 +1,113 @@
+package eats
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	config "github.com/venkat1109/cadence-codelab/common"
+	"github.com/venkat1109/cadence-codelab/eatsapp/message"
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+)
+
+func TestPriceOrderRedeemPoints(t *testing.T) {
+	menu := &common.Menu{Items: []*common.Item{
+		{ID: "0", Price: 8},
+		{ID: "1", Price: 4},
+	}}
+	tests := []struct {
+		name       string
+		pointValue float32
+		points     string
+		redeemed   int
+		total      float32
+		err        bool
+	}{
+		{name: "no points", pointValue: 0.1, total: 12},
+		{name: "zero points", pointValue: 0.1, points: "0", total: 12},
+		{name: "part of the order", pointValue: 0.1, points: "50", redeemed: 50, total: 7},
+		{name: "capped by the subtotal", pointValue: 0.1, points: "500", redeemed: 120, total: 0},
+		{name: "negative points", pointValue: 0.1, points: "-5", err: true},
+		{name: "not a number", pointValue: 0.1, points: "many", err: true},
+		{name: "redemption disabled", points: "50", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &EatsService{menu: menu, loyalty: config.LoyaltyConfig{PointValue: tt.pointValue}}
+			form := url.Values{}
+			if len(tt.points) > 0 {
+				form.Set("redeem_points", tt.points)
+			}
+			r := httptest.NewRequest("POST", "/eats-orders", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.ParseForm()
+
+			price, err := h.priceOrder(r, "joe", []string{"0", "1"})
+			if (err != nil) != tt.err {
+				t.Fatalf("priceOrder() error = %v, want error %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if price.PointsRedeemed != tt.redeemed || price.Total != tt.total || price.Subtotal != 12 {
+				t.Errorf("price = %+v, want %d points redeemed for a total of %v", price, tt.redeemed, tt.total)
+			}
+			if price.Subtotal-price.Discount != price.Total {
+				t.Errorf("discount %v does not add up to the total %v", price.Discount, price.Total)
+			}
+		})
+	}
+}
+
+func TestReservePoints(t *testing.T) {
+	tests := []struct {
+		name    string
+		balance int // no balance stored if negative
+		points  int
+		left    int
+		err     error
+	}{
+		{name: "nothing redeemed", balance: -1, points: 0, left: -1},
+		{name: "within the balance", balance: 100, points: 40, left: 60},
+		{name: "whole balance", balance: 100, points: 100, left: 0},
+		{name: "over the balance", balance: 100, points: 101, left: 100, err: errNotEnoughPoints},
+		{name: "no balance", balance: -1, points: 10, left: -1, err: errNotEnoughPoints},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &EatsService{store: store.NewMemoryStore()}
+			if tt.balance >= 0 {
+				balance := message.LoyaltyBalance{CustomerID: "joe", Points: tt.balance}
+				if err := store.PutJSON(h.store, loyaltyTable, "joe", &balance); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := h.reservePoints("joe", tt.points); err != tt.err {
+				t.Fatalf("reservePoints() = %v, want %v", err, tt.err)
+			}
+			var balance message.LoyaltyBalance
+			err := store.GetJSON(h.store, loyaltyTable, "joe", &balance)
+			if tt.left < 0 {
+				if err != store.ErrNotFound {
+					t.Errorf("balance = %+v, %v, want none", balance, err)
+				}
+				return
+			}
+			if err != nil || balance.Points != tt.left {
+				t.Errorf("balance = %d points, %v, want %d", balance.Points, err, tt.left)
+			}
+
+			// the reservation of an order that is not placed is given back
+			if tt.err == nil {
+				h.releasePoints("joe", tt.points)
+				store.GetJSON(h.store, loyaltyTable, "joe", &balance)
+				if balance.Points != tt.balance {
+					t.Errorf("balance after release = %d points, want %d", balance.Points, tt.balance)
+				}
+			}
+		})
+	}
+}
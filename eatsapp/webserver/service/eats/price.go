@@ -0,0 +1,145 @@
+package eats
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+)
+
+type (
+	// PriceService implements the handlers for requests sent to the eats
+	// pricing http service, which returns the amount charged for an order
+	// and reprices the orders modified by the restaurant.
+	PriceService struct {
+		eats *EatsService
+	}
+)
+
+const (
+	pricesTable = common.OrderPricesTable
+
+	// maxTip bounds the courier tip, which guards against typos.
+	maxTip = 100
+)
+
+// NewPriceService returns a new PriceService instance
+func NewPriceService(eats *EatsService) *PriceService {
+	return &PriceService{
+		eats: eats,
+	}
+}
+
+func (h *PriceService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.showPrice(w, r)
+	case "PATCH":
+		h.reprice(w, r)
+	default:
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+}
+
+// showPrice returns the price of the order passed as the "id" param as JSON.
+func (h *PriceService) showPrice(w http.ResponseWriter, r *http.Request) {
+	orderID := r.URL.Query().Get("id")
+	if !h.eats.authorizeOrder(w, r, orderID) {
+		return
+	}
+
+	var price common.OrderPrice
+	err := store.GetJSON(h.eats.store, pricesTable, orderID, &price)
+	if err == store.ErrNotFound {
+		http.Error(w, "Order not found: "+orderID, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(&price)
+}
+
+// reprice updates the amount charged for the order passed as the "id"
+// param to the "total" param, the price of the items of the order once
+// modified by the restaurant. The loyalty discount is kept, up to the
+// new subtotal.
+func (h *PriceService) reprice(w http.ResponseWriter, r *http.Request) {
+	if action := r.URL.Query().Get("action"); action != "reprice" {
+		http.Error(w, "Unknown price action: "+action, http.StatusUnprocessableEntity)
+		return
+	}
+
+	orderID := r.URL.Query().Get("id")
+	subtotal, err := strconv.ParseFloat(r.URL.Query().Get("total"), 32)
+	if err != nil || subtotal < 0 {
+		http.Error(w, "Invalid order total: "+r.URL.Query().Get("total"), http.StatusUnprocessableEntity)
+		return
+	}
+
+	var price common.OrderPrice
+	err = store.UpdateJSON(h.eats.store, pricesTable, orderID, &price, func() error {
+		price.Subtotal = float32(subtotal)
+		if price.Discount > price.Subtotal {
+			price.Discount = price.Subtotal
+		}
+		price.Total = price.Subtotal - price.Discount
+		return nil
+	})
+	if err == store.ErrNotFound {
+		http.Error(w, "Order not found: "+orderID, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%+v", price)
+}
+
+// priceOrder computes the price of the order, paying for part of it with
+// the loyalty points passed as the "redeem_points" form value, and adds
+// the courier tip passed as the "tip" form value.
+func (h *EatsService) priceOrder(r *http.Request, customerID string, items []string) (*common.OrderPrice, error) {
+	price := &common.OrderPrice{CustomerID: customerID}
+	for _, id := range items {
+		item, err := h.menu.GetItemByID(id)
+		if err != nil {
+			return nil, err
+		}
+		price.Subtotal += item.Price
+	}
+	price.Total = price.Subtotal
+
+	if v := r.Form.Get("tip"); len(v) > 0 {
+		tip, err := strconv.ParseFloat(v, 32)
+		if err != nil || tip < 0 || tip > maxTip {
+			return nil, fmt.Errorf("invalid tip %q, expected 0 to %v", v, maxTip)
+		}
+		price.Tip = float32(tip)
+	}
+
+	if v := r.Form.Get("redeem_points"); len(v) > 0 && v != "0" {
+		points, err := strconv.Atoi(v)
+		if err != nil || points < 0 {
+			return nil, fmt.Errorf("invalid loyalty points %q", v)
+		}
+		if h.loyalty.PointValue <= 0 {
+			return nil, errors.New("loyalty points cannot be redeemed")
+		}
+		// the discount never exceeds the order subtotal
+		if max := int(price.Subtotal / h.loyalty.PointValue); points > max {
+			points = max
+		}
+		price.PointsRedeemed = points
+		price.Discount = float32(points) * h.loyalty.PointValue
+		price.Total = price.Subtotal - price.Discount
+	}
+	return price, nil
+}
@@ -30,13 +30,24 @@ func (h *EatsService) show(w http.ResponseWriter, r *http.Request) {
 
 func (h *EatsService) showOrder(
 	w http.ResponseWriter, r *http.Request, orderID string, runID string) error {
+	if !h.authorizeOrder(w, r, orderID) {
+		return errOrderNotFound
+	}
+
 	data, err := h.processExecution(orderID, runID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return err
 	}
 
-	return service.ViewHandler(w, r, *data)
+	page := EatsOrderStatusPage{
+		TaskGroup:        data,
+		AwaitingFeedback: awaitingFeedback(data),
+	}
+	page.Contact, _ = h.getContact(orderID)
+	page.Substitution = h.substitutionPage(orderID)
+	page.Changes = h.changesPage(orderID)
+	return service.ViewHandler(w, r, page)
 }
 
 func (h *EatsService) processExecution(workflowID string, runID string) (*TaskGroup, error) {
@@ -45,16 +56,32 @@ func (h *EatsService) processExecution(workflowID string, runID string) (*TaskGr
 }
 
 func (h *EatsService) listOrders(w http.ResponseWriter, r *http.Request) error {
+	filter, err := parseOrderListFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return err
+	}
+
 	page := EatsOrderListPage{
 		ShowOrderExistError: (r.URL.Query().Get("error") == "order_exist"),
+		Filter:              filter,
+		Tabs:                OrderTabs,
+		Statuses:            closeStatusNames(),
+		WorkflowTypes:       workflowTypeNames(),
 	}
 
-	var err error
-	page.Orders, err = h.listOpenWorkflows()
+	var nextPage string
+	page.Orders, nextPage, err = h.listOrderPage(r, filter)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return err
 	}
+	if len(nextPage) > 0 {
+		page.NextPageURL = filter.URL(nextPage)
+	}
+	if len(filter.PageToken) > 0 {
+		page.FirstPageURL = filter.URL("")
+	}
 
 	return service.ViewHandler(w, r, page)
 }
@@ -0,0 +1,202 @@
+package eats
+
+import (
+	"fmt"
+	"net/http"
+
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/eats"
+)
+
+type (
+	// SubstitutionRequest models a request asking the customer to
+	// replace the items of an order that became unavailable.
+	SubstitutionRequest struct {
+		OrderID     string
+		Items       []string
+		Unavailable []string
+		WorkflowID  string
+		RunID       string
+	}
+
+	// SubstitutionChoice models a menu item offered as a substitution.
+	SubstitutionChoice struct {
+		Item     *common.Item
+		Selected bool
+	}
+
+	// SubstitutionPage models the substitution form on the order status page.
+	SubstitutionPage struct {
+		*SubstitutionRequest
+		Unavailable []*common.Item
+		Choices     []*SubstitutionChoice
+	}
+
+	// SubstitutionService implements the handlers for requests
+	// sent to the eats substitution http service
+	SubstitutionService struct {
+		eats *EatsService
+	}
+)
+
+const (
+	substitutionsTable = "eats-substitutions"
+)
+
+// NewSubstitutionService returns a new SubstitutionService instance
+func NewSubstitutionService(eats *EatsService) *SubstitutionService {
+	return &SubstitutionService{
+		eats: eats,
+	}
+}
+
+func (h *SubstitutionService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "POST":
+		h.addRequest(w, r)
+	case "PATCH":
+		h.respond(w, r)
+	default:
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+}
+
+// addRequest records the substitution request sent by the eats workflow.
+func (h *SubstitutionService) addRequest(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	req := SubstitutionRequest{
+		OrderID:     r.Form.Get("id"),
+		Items:       r.Form["item"],
+		Unavailable: r.Form["unavailable"],
+		WorkflowID:  r.Form.Get("workflow_id"),
+		RunID:       r.Form.Get("run_id"),
+	}
+	err = store.PutJSON(h.eats.store, substitutionsTable, req.OrderID, &req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%+v", req)
+}
+
+// respond sends the items picked by the customer, or the
+// cancellation of the order, to the eats order workflow.
+func (h *SubstitutionService) respond(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	orderID := r.URL.Query().Get("id")
+	if !h.eats.authorizeOrder(w, r, orderID) {
+		return
+	}
+	req, err := h.eats.getSubstitution(orderID)
+	if err == store.ErrNotFound {
+		http.Error(w, "Order not found: "+orderID, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var response eats.Substitution
+	switch action := r.URL.Query().Get("action"); action {
+	case "substitute":
+		response.Items = r.Form["item-id"]
+		if len(response.Items) == 0 {
+			http.Error(w, "Order constains no items!", http.StatusUnprocessableEntity)
+			return
+		}
+		if unavailable := h.eats.menu.Unavailable(response.Items); len(unavailable) > 0 {
+			http.Error(w, "Items not available: "+h.eats.itemNames(unavailable), http.StatusUnprocessableEntity)
+			return
+		}
+	case "cancel":
+		response.Cancel = true
+	default:
+		http.Error(w, "Unknown update action: "+action, http.StatusUnprocessableEntity)
+		return
+	}
+
+	err = h.eats.client.SignalWorkflow(req.WorkflowID, req.RunID, eats.SubstitutionSignalName, response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.eats.store.Delete(substitutionsTable, orderID)
+	fmt.Fprintf(w, "%+v", response)
+}
+
+// getSubstitution returns the pending substitution request for the order.
+func (h *EatsService) getSubstitution(orderID string) (*SubstitutionRequest, error) {
+	var req SubstitutionRequest
+	err := store.GetJSON(h.store, substitutionsTable, orderID, &req)
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// substitutionPage returns the substitution form for the order, or nil
+// if the customer was not asked to substitute any items.
+func (h *EatsService) substitutionPage(orderID string) *SubstitutionPage {
+	req, err := h.getSubstitution(orderID)
+	if err != nil {
+		return nil
+	}
+
+	page := &SubstitutionPage{
+		SubstitutionRequest: req,
+	}
+	for _, id := range req.Unavailable {
+		if item, err := h.menu.GetItemByID(id); err == nil {
+			page.Unavailable = append(page.Unavailable, item)
+		}
+	}
+	for _, item := range h.menu.Snapshot().Items {
+		if !item.InStock() {
+			continue
+		}
+		page.Choices = append(page.Choices, &SubstitutionChoice{
+			Item:     item,
+			Selected: contains(req.Items, item.ID) && !contains(req.Unavailable, item.ID),
+		})
+	}
+	return page
+}
+
+// itemNames returns the names of the items, for use in error messages.
+func (h *EatsService) itemNames(ids []string) string {
+	names := ""
+	for i, id := range ids {
+		if i > 0 {
+			names += ", "
+		}
+		if item, err := h.menu.GetItemByID(id); err == nil {
+			names += item.Name
+		} else {
+			names += id
+		}
+	}
+	return names
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -24,6 +24,7 @@ type (
 		ID      string
 		RunID   string
 		Status  TaskGroupStatus
+		Reason  string
 		Tasks   []*Task
 		TaskMap map[int64]*Task
 		History *s.History
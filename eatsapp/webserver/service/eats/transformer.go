@@ -171,6 +171,9 @@ func (h *TaskGroupExecution) tfWorkflowExecutionCompleted(event *s.HistoryEvent,
 
 func (h *TaskGroupExecution) tfWorkflowExecutionFailed(event *s.HistoryEvent, tasks *TaskGroup) error {
 	tasks.Status = "f"
+	if attr := event.WorkflowExecutionFailedEventAttributes; attr != nil && attr.Reason != nil {
+		tasks.Reason = *attr.Reason
+	}
 	return nil
 }
 
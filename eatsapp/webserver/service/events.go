@@ -0,0 +1,337 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+	"go.uber.org/zap"
+)
+
+type (
+	// Broker pushes events to the pages subscribed to a topic, using
+	// server-sent events. Topics are "restaurant", "courier" and
+	// "order:<id>" for the status page of an eats order. Only the topics
+	// with an authorizer can be subscribed to.
+	Broker struct {
+		sync.Mutex
+		subscribers map[string]map[chan *Event]string // channels by topic, to their watch key
+		authorizers map[string]AuthorizeFunc
+		watchers    map[string]*watcher
+		watches     map[string]*watch // by watch key
+		orderTables map[string]bool
+		nextID      int64
+		done        chan struct{}
+	}
+
+	// Event models a change pushed to the subscribers of a topic.
+	Event struct {
+		ID    int64
+		Topic string
+		Type  string
+		Data  interface{}
+	}
+
+	// AuthorizeFunc returns true if the user of the request may subscribe
+	// to the topic.
+	AuthorizeFunc func(r *http.Request, topic string) bool
+
+	// WatchFunc publishes the events of a topic that are not caused by
+	// webserver state changes, e.g. by polling a workflow history. It runs
+	// while the watch has subscribers and returns when ctx is done. The
+	// changed channel receives whenever a store change is published to
+	// the topic, which is when new events are most likely.
+	WatchFunc func(ctx context.Context, publish PublishFunc, changed <-chan struct{}, topic string, query url.Values)
+
+	// PublishFunc sends an event to the subscribers of a watch.
+	PublishFunc func(eventType string, data interface{})
+
+	// watcher models a WatchFunc along with the query params that tell
+	// the watches of a topic apart.
+	watcher struct {
+		fn     WatchFunc
+		params []string
+	}
+
+	// watch models a running WatchFunc and the count of its subscribers.
+	watch struct {
+		cancel      context.CancelFunc
+		changed     chan struct{}
+		subscribers int
+	}
+)
+
+// Values representing event types, along with the store.ChangeType values.
+const (
+	EventWorkflow = "workflow"
+)
+
+const (
+	// OrderTopicPrefix prefixes the topic of an eats order.
+	OrderTopicPrefix = "order:"
+
+	subscriberBuffer  = 16
+	heartbeatInterval = time.Second * 15
+	retryInterval     = 3000 // milliseconds
+)
+
+// NewBroker returns a new Broker without subscribers.
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: make(map[string]map[chan *Event]string),
+		authorizers: make(map[string]AuthorizeFunc),
+		watchers:    make(map[string]*watcher),
+		watches:     make(map[string]*watch),
+		orderTables: make(map[string]bool),
+		done:        make(chan struct{}),
+	}
+}
+
+// Close ends the event streams, which would otherwise keep the server
+// from shutting down, and stops the watches.
+func (b *Broker) Close() {
+	b.Lock()
+	defer b.Unlock()
+	select {
+	case <-b.done:
+		return
+	default:
+	}
+	close(b.done)
+	for key, w := range b.watches {
+		w.cancel()
+		delete(b.watches, key)
+	}
+}
+
+// Authorize lets the users fn authorizes subscribe to the topics starting
+// with the prefix.
+func (b *Broker) Authorize(prefix string, fn AuthorizeFunc) {
+	b.Lock()
+	defer b.Unlock()
+	b.authorizers[prefix] = fn
+}
+
+// RoleTopic returns an AuthorizeFunc allowing the users with one of the roles.
+func RoleTopic(roles ...string) AuthorizeFunc {
+	return func(r *http.Request, topic string) bool {
+		user := CurrentUser(r)
+		return user != nil && HasRole(user, roles)
+	}
+}
+
+// authorized returns true if the user of the request may subscribe to the
+// topic, topics without an authorizer are denied to all.
+func (b *Broker) authorized(r *http.Request, topic string) bool {
+	b.Lock()
+	var authorize AuthorizeFunc
+	for prefix, fn := range b.authorizers {
+		if strings.HasPrefix(topic, prefix) {
+			authorize = fn
+			break
+		}
+	}
+	b.Unlock()
+	return authorize != nil && authorize(r, topic)
+}
+
+// Watch runs fn for the subscribed topics starting with the prefix, once
+// for every distinct value of the query params of the subscriptions, e.g.
+// once for every run ID of the workflow of the topic.
+func (b *Broker) Watch(prefix string, fn WatchFunc, params ...string) {
+	b.Lock()
+	defer b.Unlock()
+	b.watchers[prefix] = &watcher{fn: fn, params: params}
+}
+
+// OrderTables publishes the changes of the tables, which must be keyed by
+// eats order ID, to the topic of the order.
+func (b *Broker) OrderTables(tables ...string) {
+	b.Lock()
+	defer b.Unlock()
+	for _, table := range tables {
+		b.orderTables[table] = true
+	}
+}
+
+// Subscribe returns a channel receiving the events published to the topic,
+// and the function to call to unsubscribe. The query is passed to the
+// watcher of the topic, if any, when the watch gets its first subscriber.
+func (b *Broker) Subscribe(topic string, query url.Values) (<-chan *Event, func()) {
+	b.Lock()
+	defer b.Unlock()
+
+	ch := make(chan *Event, subscriberBuffer)
+	if b.subscribers[topic] == nil {
+		b.subscribers[topic] = make(map[chan *Event]string)
+	}
+	key := b.startWatch(topic, query)
+	b.subscribers[topic][ch] = key
+
+	return ch, func() {
+		b.Lock()
+		defer b.Unlock()
+		delete(b.subscribers[topic], ch)
+		if len(b.subscribers[topic]) == 0 {
+			delete(b.subscribers, topic)
+		}
+		if w, ok := b.watches[key]; ok {
+			w.subscribers--
+			if w.subscribers == 0 {
+				w.cancel()
+				delete(b.watches, key)
+			}
+		}
+	}
+}
+
+// startWatch subscribes to the watch of the topic and query, starting it
+// if it is not running, and returns its key, empty if the topic has no
+// watcher. Must be called with the lock held.
+func (b *Broker) startWatch(topic string, query url.Values) string {
+	for prefix, watcher := range b.watchers {
+		if !strings.HasPrefix(topic, prefix) {
+			continue
+		}
+		values := url.Values{}
+		for _, param := range watcher.params {
+			values.Set(param, query.Get(param))
+		}
+		key := topic + "?" + values.Encode()
+
+		w, ok := b.watches[key]
+		if !ok {
+			ctx, cancel := context.WithCancel(context.Background())
+			w = &watch{cancel: cancel, changed: make(chan struct{}, 1)}
+			b.watches[key] = w
+			publish := func(eventType string, data interface{}) {
+				b.publish(topic, key, eventType, data)
+			}
+			go watcher.fn(ctx, publish, w.changed, topic, query)
+		}
+		w.subscribers++
+		return key
+	}
+	return ""
+}
+
+// Publish sends the event to the subscribers of the topic. Subscribers
+// that are not keeping up miss the event, pages reload their whole
+// content on every event so only the last event matters.
+func (b *Broker) Publish(topic string, eventType string, data interface{}) {
+	b.publish(topic, "", eventType, data)
+}
+
+// publish sends the event to the subscribers of the topic, only to the
+// subscribers of the watch if key is not empty. Events not coming from a
+// watch wake the watches of the topic.
+func (b *Broker) publish(topic string, key string, eventType string, data interface{}) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.nextID++
+	event := &Event{
+		ID:    b.nextID,
+		Topic: topic,
+		Type:  eventType,
+		Data:  data,
+	}
+	for ch, watchKey := range b.subscribers[topic] {
+		if len(key) > 0 && watchKey != key {
+			continue
+		}
+		select {
+		case ch <- event:
+		default:
+		}
+		if w, ok := b.watches[watchKey]; ok && len(key) == 0 {
+			select {
+			case w.changed <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// StoreChanged publishes the change to the topic of the page showing the
+// changed record. It is the observer of the store used by the services.
+func (b *Broker) StoreChanged(change store.Change) {
+	b.Lock()
+	orderTable := b.orderTables[change.Table]
+	b.Unlock()
+
+	var topic string
+	switch {
+	case strings.HasPrefix(change.Table, "restaurant-"):
+		topic = "restaurant"
+	case strings.HasPrefix(change.Table, "courier-"):
+		topic = "courier"
+	case orderTable:
+		topic = OrderTopicPrefix + change.Key
+	default:
+		return
+	}
+	b.Publish(topic, string(change.Type), change)
+
+	// restaurant orders show on the status page of the eats order too
+	if change.Table == "restaurant-orders" {
+		b.Publish(OrderTopicPrefix+change.Key, string(change.Type), change)
+	}
+}
+
+// ServeHTTP streams the events of the "topic" param as server-sent events.
+func (b *Broker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	topic := r.URL.Query().Get("topic")
+	if len(topic) == 0 {
+		http.Error(w, "No topic specified!", http.StatusUnprocessableEntity)
+		return
+	}
+	// customers cannot tell which orders exist
+	if !b.authorized(r, topic) {
+		http.Error(w, "Topic not found: "+topic, http.StatusNotFound)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	events, unsubscribe := b.Subscribe(topic, r.URL.Query())
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	fmt.Fprintf(w, "retry: %d\n\n", retryInterval)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case event := <-events:
+			data, err := json.Marshal(event)
+			if err != nil {
+				logger.Error("Failed to encode event", zap.String("topic", event.Topic), zap.Error(err))
+				continue
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+			flusher.Flush()
+		case <-heartbeat.C:
+			// keeps proxies from closing the idle connection
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		case <-b.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+)
+
+func TestBrokerStoreChanged(t *testing.T) {
+	tests := []struct {
+		name   string
+		change store.Change
+		topics []string
+	}{
+		{"restaurant record", store.Change{Table: "restaurant-menu", Key: "m1"}, []string{"restaurant"}},
+		{"restaurant order", store.Change{Table: "restaurant-orders", Key: "o1"}, []string{"restaurant", "order:o1"}},
+		{"courier record", store.Change{Table: "courier-jobs", Key: "o1"}, []string{"courier"}},
+		{"order table", store.Change{Table: "eats-order-prices", Key: "o1"}, []string{"order:o1"}},
+		{"table keyed by customer", store.Change{Table: "eats-loyalty", Key: "joe"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBroker()
+			defer b.Close()
+			b.OrderTables(OrderPricesTable)
+
+			subscribed := []string{"restaurant", "courier", "order:o1", "order:joe"}
+			events := make(map[string]<-chan *Event)
+			for _, topic := range subscribed {
+				ch, unsubscribe := b.Subscribe(topic, nil)
+				defer unsubscribe()
+				events[topic] = ch
+			}
+			b.StoreChanged(tt.change)
+
+			got := make(map[string]bool)
+			for _, topic := range subscribed {
+				select {
+				case <-events[topic]:
+					got[topic] = true
+				default:
+				}
+			}
+			if len(got) != len(tt.topics) {
+				t.Errorf("published to %v, want %v", got, tt.topics)
+			}
+			for _, topic := range tt.topics {
+				if !got[topic] {
+					t.Errorf("not published to %s", topic)
+				}
+			}
+		})
+	}
+}
+
+func TestBrokerWakesWatch(t *testing.T) {
+	b := NewBroker()
+	defer b.Close()
+	b.OrderTables(OrderPricesTable)
+
+	woken := make(chan struct{})
+	b.Watch(OrderTopicPrefix, func(ctx context.Context, publish PublishFunc, changed <-chan struct{}, topic string, query url.Values) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-changed:
+				publish(EventWorkflow, nil)
+				woken <- struct{}{}
+			}
+		}
+	})
+	_, unsubscribe := b.Subscribe("order:o1", url.Values{})
+	defer unsubscribe()
+
+	// events published by the watch itself must not wake it
+	b.StoreChanged(store.Change{Table: OrderPricesTable, Key: "o1"})
+	for i := 0; i < 2; i++ {
+		select {
+		case <-woken:
+			if i > 0 {
+				t.Fatal("watch woken by its own event")
+			}
+		case <-time.After(100 * time.Millisecond):
+			if i == 0 {
+				t.Fatal("watch not woken by the store change")
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"html/template"
+	"time"
+)
+
+// dateTimeLayouts lists the layouts of the dates and times shown on the
+// pages by locale, other locales use the layout of the default locale.
+var dateTimeLayouts = map[string]string{
+	"en": "Mon Jan 2 15:04",
+	"fr": "02/01/2006 15:04",
+	"es": "02/01/2006 15:04",
+}
+
+// statusLabels maps the statuses of the orders, jobs and workflows to the
+// bootstrap label they are shown with, other statuses use label-default.
+var statusLabels = map[string]string{
+	"OPEN":            "label-info",
+	"PENDING":         "label-info",
+	"ACCEPTED":        "label-info",
+	"PREPARING":       "label-info",
+	"READY":           "label-info",
+	"PICKED_UP":       "label-info",
+	"COMPLETED":       "label-success",
+	"DELIVERED":       "label-success",
+	"SENT":            "label-success",
+	"PAID":            "label-success",
+	"CANCELED":        "label-warning",
+	"TIMED_OUT":       "label-warning",
+	"RETURNED":        "label-warning",
+	"DELIVERY_FAILED": "label-warning",
+	"FAILED":          "label-danger",
+	"TERMINATED":      "label-danger",
+	"REJECTED":        "label-danger",
+	"DISPOSED":        "label-danger",
+}
+
+// FuncMap returns the template funcs shared by the pages, bound to the
+// locale and currency of the localizer:
+//
+//	t "message" args...   the translated message
+//	money .Price          the amount in the restaurant currency
+//	currencySymbol        the symbol of the restaurant currency
+//	locale                the locale of the page, e.g. for <html lang>
+//	datetime .StartedAt   the date and time in the layout of the locale
+//	clock .CreatedAt      the time of the day, 15:04:05
+//	nanos .Timestamp      the time of the unix nanoseconds of the events
+//	statusLabel .Status   the translated status in its bootstrap label
+func (l *Localizer) FuncMap() template.FuncMap {
+	layout, ok := dateTimeLayouts[l.Locale]
+	if !ok {
+		layout = dateTimeLayouts[DefaultLocale]
+	}
+	return template.FuncMap{
+		"t":              l.Translate,
+		"money":          l.money.Format,
+		"currencySymbol": func() string { return l.money.Symbol },
+		"locale":         func() string { return l.Locale },
+		"datetime":       func(t time.Time) string { return formatTime(t, layout) },
+		"clock":          func(t time.Time) string { return formatTime(t, "15:04:05") },
+		"nanos":          func(ns int64) time.Time { return time.Unix(0, ns) },
+		"statusLabel":    l.statusLabel,
+	}
+}
+
+// statusLabel returns the status translated in the bootstrap label of the
+// status, the status text being escaped.
+func (l *Localizer) statusLabel(status string) template.HTML {
+	class, ok := statusLabels[status]
+	if !ok {
+		class = "label-default"
+	}
+	return template.HTML(`<span class="label ` + class + `">` +
+		template.HTMLEscapeString(l.Translate(status)) + `</span>`)
+}
+
+// formatTime formats the time with the layout, zero times as empty.
+func formatTime(t time.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(layout)
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	config "github.com/venkat1109/cadence-codelab/common"
+)
+
+type (
+	// Hours models the weekly opening hours of the restaurant along with
+	// its holiday closures, in the time zone of the restaurant.
+	Hours struct {
+		loc        *time.Location
+		days       [7]*openInterval // indexed by time.Weekday, nil when closed
+		holidays   map[string]bool  // dates in the restaurant time zone
+		alwaysOpen bool
+		// Schedule is true when orders placed outside opening hours are
+		// scheduled for the next opening instead of being rejected.
+		Schedule bool
+	}
+
+	// openInterval is an offset from midnight, closes <= opens means
+	// the restaurant closes after midnight.
+	openInterval struct {
+		opens  time.Duration
+		closes time.Duration
+	}
+)
+
+const dateFormat = "2006-01-02"
+
+// maxClosedDays bounds the search for the next opening.
+const maxClosedDays = 31
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// NewHours returns the opening hours described by the restaurant configuration.
+// Days missing from Hours are closed, and the restaurant is always open when
+// no hours are configured.
+func NewHours(cfg config.RestaurantConfig) (*Hours, error) {
+	h := &Hours{
+		loc:        time.Local,
+		holidays:   make(map[string]bool),
+		alwaysOpen: len(cfg.Hours) == 0,
+		Schedule:   cfg.AfterHours == "schedule",
+	}
+
+	if len(cfg.TimeZone) > 0 {
+		loc, err := time.LoadLocation(cfg.TimeZone)
+		if err != nil {
+			return nil, err
+		}
+		h.loc = loc
+	}
+
+	for day, value := range cfg.Hours {
+		weekday, ok := weekdays[strings.ToLower(day)]
+		if !ok {
+			return nil, fmt.Errorf("invalid week day %q", day)
+		}
+		if value == "closed" {
+			h.days[weekday] = nil
+			continue
+		}
+		interval, err := parseInterval(value)
+		if err != nil {
+			return nil, err
+		}
+		h.days[weekday] = interval
+	}
+
+	for _, date := range cfg.Holidays {
+		if _, err := time.ParseInLocation(dateFormat, date, h.loc); err != nil {
+			return nil, fmt.Errorf("invalid holiday %q: %v", date, err)
+		}
+		h.holidays[date] = true
+	}
+	return h, nil
+}
+
+// Location returns the time zone of the restaurant.
+func (h *Hours) Location() *time.Location {
+	return h.loc
+}
+
+// AlwaysOpen returns true if the restaurant has no opening hours.
+func (h *Hours) AlwaysOpen() bool {
+	return h.alwaysOpen
+}
+
+// IsOpen returns true if the time falls within the opening hours.
+func (h *Hours) IsOpen(t time.Time) bool {
+	if h.alwaysOpen {
+		return true
+	}
+	opensAt, _, ok := h.Interval(t)
+	return ok && !opensAt.After(t)
+}
+
+// Interval returns the opening and closing time of the interval the time
+// falls in, or of the next interval if the restaurant is closed. It returns
+// false if the restaurant does not open in the foreseeable future.
+func (h *Hours) Interval(t time.Time) (time.Time, time.Time, bool) {
+	t = t.In(h.loc)
+	// start the day before, intervals may extend past midnight
+	day := time.Date(t.Year(), t.Month(), t.Day()-1, 0, 0, 0, 0, h.loc)
+	for i := 0; i <= maxClosedDays; i++ {
+		opensAt, closesAt, ok := h.on(day.AddDate(0, 0, i))
+		if ok && closesAt.After(t) {
+			return opensAt, closesAt, true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// NextOpening returns the time the restaurant opens after the time,
+// or the zero time if it does not open in the foreseeable future.
+func (h *Hours) NextOpening(t time.Time) time.Time {
+	opensAt, closesAt, ok := h.Interval(t)
+	if !ok {
+		return time.Time{}
+	}
+	if opensAt.After(t) {
+		return opensAt
+	}
+	opensAt, _, ok = h.Interval(closesAt)
+	if !ok {
+		return time.Time{}
+	}
+	return opensAt
+}
+
+// on returns the opening interval that starts on the day.
+func (h *Hours) on(day time.Time) (time.Time, time.Time, bool) {
+	interval := h.days[day.Weekday()]
+	if interval == nil || h.holidays[day.Format(dateFormat)] {
+		return time.Time{}, time.Time{}, false
+	}
+	opensAt := h.at(day, interval.opens)
+	closesAt := h.at(day, interval.closes)
+	if interval.closes <= interval.opens {
+		closesAt = h.at(day.AddDate(0, 0, 1), interval.closes)
+	}
+	return opensAt, closesAt, true
+}
+
+// at returns the wall clock time of day on the day, in the time zone of the
+// restaurant. Adding the offset to midnight would be an hour off on the days
+// daylight saving time starts or ends.
+func (h *Hours) at(day time.Time, timeOfDay time.Duration) time.Time {
+	hour := int(timeOfDay / time.Hour)
+	min := int(timeOfDay % time.Hour / time.Minute)
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, min, 0, 0, h.loc)
+}
+
+// parseInterval parses opening hours formatted as HH:MM-HH:MM.
+func parseInterval(value string) (*openInterval, error) {
+	parts := strings.Split(value, "-")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid opening hours %q", value)
+	}
+	opens, err := ParseTimeOfDay(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return nil, err
+	}
+	closes, err := ParseTimeOfDay(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return nil, err
+	}
+	return &openInterval{opens: opens, closes: closes}, nil
+}
+
+// ParseTimeOfDay parses a HH:MM time of day into an offset from midnight.
+func ParseTimeOfDay(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q: %v", value, err)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
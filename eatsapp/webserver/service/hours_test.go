@@ -0,0 +1,58 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	config "github.com/venkat1109/cadence-codelab/common"
+)
+
+func TestHoursInterval(t *testing.T) {
+	hours, err := NewHours(config.RestaurantConfig{
+		TimeZone: "America/Los_Angeles",
+		Hours: map[string]string{
+			"fri": "08:00-23:30",
+			"sat": "10:00-02:00",
+			"sun": "10:00-20:00",
+		},
+		Holidays: []string{"2017-12-25"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc := hours.Location()
+	at := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2017, month, day, hour, min, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name   string
+		t      time.Time
+		open   bool
+		opens  time.Time
+		closes time.Time
+	}{
+		{"open", at(time.November, 3, 12, 0), true, at(time.November, 3, 8, 0), at(time.November, 3, 23, 30)},
+		{"before opening", at(time.November, 3, 7, 0), false, at(time.November, 3, 8, 0), at(time.November, 3, 23, 30)},
+		{"after midnight", at(time.November, 5, 1, 0), true, at(time.November, 4, 10, 0), at(time.November, 5, 2, 0)},
+		{"closed day", at(time.November, 6, 12, 0), false, at(time.November, 10, 8, 0), at(time.November, 10, 23, 30)},
+		{"holiday", at(time.December, 24, 21, 0), false, at(time.December, 29, 8, 0), at(time.December, 29, 23, 30)},
+		// daylight saving time starts at 2:00 on Sunday, March 12 2017
+		{"dst start", at(time.March, 12, 9, 30), false, at(time.March, 12, 10, 0), at(time.March, 12, 20, 0)},
+		{"dst start open", at(time.March, 12, 19, 30), true, at(time.March, 12, 10, 0), at(time.March, 12, 20, 0)},
+		// daylight saving time ends at 2:00 on Sunday, November 5 2017
+		{"dst end", at(time.November, 5, 9, 30), false, at(time.November, 5, 10, 0), at(time.November, 5, 20, 0)},
+		{"dst end open", at(time.November, 5, 19, 30), true, at(time.November, 5, 10, 0), at(time.November, 5, 20, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if open := hours.IsOpen(tt.t); open != tt.open {
+				t.Errorf("IsOpen() = %v, want %v", open, tt.open)
+			}
+			opens, closes, ok := hours.Interval(tt.t)
+			if !ok || !opens.Equal(tt.opens) || !closes.Equal(tt.closes) {
+				t.Errorf("Interval() = %v - %v, want %v - %v", opens, closes, tt.opens, tt.closes)
+			}
+		})
+	}
+}
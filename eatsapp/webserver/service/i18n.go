@@ -0,0 +1,146 @@
+package service
+
+import (
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+type (
+	// Catalog maps the English messages of the templates to their
+	// translation. Messages missing from a catalog stay in English.
+	Catalog map[string]string
+
+	// Localizer translates the messages and formats the amounts of the
+	// pages rendered for a locale.
+	Localizer struct {
+		Locale  string
+		catalog Catalog
+		money   *MoneyFormat
+	}
+)
+
+const (
+	// DefaultLocale is the locale of the templates, used when the
+	// browser accepts none of the locales of the catalogs.
+	DefaultLocale = "en"
+
+	// CatalogsGlob stores the value used to glob for message catalogs in
+	// the assets, the file name of a catalog being its locale: fr.yaml.
+	CatalogsGlob = "i18n/*.yaml"
+)
+
+// localizedTemplates stores the templates parsed for every locale.
+var localizedTemplates map[string]*template.Template
+
+// loadCatalogs returns the message catalogs of the assets by locale.
+func loadCatalogs(assets fs.FS) (map[string]Catalog, error) {
+	files, err := fs.Glob(assets, CatalogsGlob)
+	if err != nil {
+		return nil, err
+	}
+	catalogs := make(map[string]Catalog, len(files))
+	for _, file := range files {
+		data, err := fs.ReadFile(assets, file)
+		if err != nil {
+			return nil, err
+		}
+		var catalog Catalog
+		if err := yaml.Unmarshal(data, &catalog); err != nil {
+			return nil, fmt.Errorf("invalid catalog %s: %v", file, err)
+		}
+		locale := strings.ToLower(strings.TrimSuffix(path.Base(file), path.Ext(file)))
+		catalogs[locale] = catalog
+	}
+	return catalogs, nil
+}
+
+// NewLocalizer returns the localizer of the locale, formatting amounts in
+// the currency. A nil catalog leaves the messages in English.
+func NewLocalizer(locale string, catalog Catalog, currency string) *Localizer {
+	return &Localizer{
+		Locale:  locale,
+		catalog: catalog,
+		money:   NewMoneyFormat(locale, currency),
+	}
+}
+
+// Translate returns the translation of the message, formatted with the
+// args as by fmt.Sprintf when there are any.
+func (l *Localizer) Translate(msg string, args ...interface{}) string {
+	if translation, ok := l.catalog[msg]; ok && len(translation) > 0 {
+		msg = translation
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Locales returns the locales the templates are rendered in.
+func Locales() []string {
+	locales := make([]string, 0, len(localizedTemplates))
+	for locale := range localizedTemplates {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// NegotiateLocale returns the locale the browser prefers among the locales
+// of the templates, from the Accept-Language header. A regional locale,
+// e.g. fr-CA, falls back to its language.
+func NegotiateLocale(r *http.Request) string {
+	best, bestQ := DefaultLocale, 0.0
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				v, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+				if err != nil {
+					v = 0
+				}
+				q = v
+			}
+		}
+		if q <= bestQ {
+			continue
+		}
+		if locale, ok := matchLocale(tag); ok {
+			best, bestQ = locale, q
+		}
+	}
+	return best
+}
+
+func matchLocale(tag string) (string, bool) {
+	tag = strings.Replace(tag, "_", "-", -1)
+	if _, ok := localizedTemplates[tag]; ok {
+		return tag, true
+	}
+	if i := strings.Index(tag, "-"); i > 0 {
+		if _, ok := localizedTemplates[tag[:i]]; ok {
+			return tag[:i], true
+		}
+	}
+	return "", false
+}
+
+// templatesFor returns the templates of the locale the request prefers.
+func templatesFor(r *http.Request) (*template.Template, string) {
+	locale := NegotiateLocale(r)
+	if t, ok := localizedTemplates[locale]; ok {
+		return t, locale
+	}
+	return Templates, DefaultLocale
+}
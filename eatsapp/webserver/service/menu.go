@@ -0,0 +1,116 @@
+package service
+
+import (
+	"sort"
+	"strconv"
+)
+
+// UnmarshalYAML decodes an item from the menu file,
+// items are available unless the file says otherwise.
+func (i *Item) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain Item
+	item := plain{Available: true}
+	if err := unmarshal(&item); err != nil {
+		return err
+	}
+	*i = Item(item)
+	return nil
+}
+
+// InStock returns true if the item can be ordered, that is it was not
+// taken off the menu and, when stock is tracked, some are left.
+func (i *Item) InStock() bool {
+	return i.Available && (!i.TrackStock || i.Stock > 0)
+}
+
+// Unavailable returns the IDs of the items that cannot be ordered.
+func (m *Menu) Unavailable(ids []string) []string {
+	var unavailable []string
+	for _, id := range ids {
+		item, err := m.GetItemByID(id)
+		if err != nil || !item.InStock() {
+			unavailable = append(unavailable, id)
+		}
+	}
+	return unavailable
+}
+
+// Snapshot returns a copy of the menu that is safe to
+// render while the menu is being changed.
+func (m *Menu) Snapshot() *Menu {
+	m.RLock()
+	defer m.RUnlock()
+
+	items := make([]*Item, len(m.Items))
+	copy(items, m.Items)
+	return &Menu{Items: items}
+}
+
+// Put adds the item to the menu or replaces the item with the same ID.
+// Items older than the version already on the menu are ignored.
+func (m *Menu) Put(item *Item) {
+	m.Lock()
+	defer m.Unlock()
+
+	for i, v := range m.Items {
+		if v.ID == item.ID {
+			if v.Version <= item.Version {
+				m.Items[i] = item
+			}
+			return
+		}
+	}
+	m.Items = append(m.Items, item)
+	sortItems(m.Items)
+}
+
+// Remove removes the item with the specified ID from the menu.
+func (m *Menu) Remove(id string) {
+	m.Lock()
+	defer m.Unlock()
+
+	for i, v := range m.Items {
+		if v.ID == id {
+			m.Items = append(m.Items[:i:i], m.Items[i+1:]...)
+			return
+		}
+	}
+}
+
+// Replace replaces all the items on the menu.
+func (m *Menu) Replace(items []*Item) {
+	sortItems(items)
+
+	m.Lock()
+	defer m.Unlock()
+	m.Items = items
+}
+
+// NextID returns an ID that is not used by any item on the menu.
+func (m *Menu) NextID() string {
+	m.RLock()
+	defer m.RUnlock()
+
+	max := 0
+	for _, v := range m.Items {
+		if id, err := strconv.Atoi(v.ID); err == nil && id > max {
+			max = id
+		}
+	}
+	return strconv.Itoa(max + 1)
+}
+
+// sortItems orders items by ID, numeric IDs first in numeric order.
+func sortItems(items []*Item) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, errA := strconv.Atoi(items[i].ID)
+		b, errB := strconv.Atoi(items[j].ID)
+		switch {
+		case errA == nil && errB == nil:
+			return a < b
+		case errA == nil || errB == nil:
+			return errA == nil
+		}
+		return items[i].ID < items[j].ID
+	})
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+type (
+	// Middleware wraps a handler with behavior common to all the routes.
+	Middleware func(h http.Handler) http.Handler
+
+	// statusWriter records the status and size of the response
+	// written by the wrapped handler, for the access log and metrics.
+	statusWriter struct {
+		http.ResponseWriter
+		status int
+		size   int
+	}
+
+	// gzipWriter compresses the response body, once the handler has
+	// written the headers and they show the body is worth compressing.
+	gzipWriter struct {
+		http.ResponseWriter
+		gz      *gzip.Writer
+		decided bool
+	}
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+
+	requestIDKey contextKey = "request_id"
+)
+
+// Chain wraps h with the middlewares, the first one being the outermost.
+func Chain(h http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+// RequestID assigns every request an ID, taken from the X-Request-ID header
+// when the client sent one, which is returned in the same response header.
+func RequestID(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if len(id) == 0 || len(id) > 128 {
+			token, err := randomToken()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			id = token[:16]
+		}
+		w.Header().Set(requestIDHeader, id)
+		h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey, id)))
+	})
+}
+
+// RequestIDOf returns the ID assigned to the request by RequestID.
+func RequestIDOf(r *http.Request) string {
+	id, _ := r.Context().Value(requestIDKey).(string)
+	return id
+}
+
+// AccessLog logs every request once its response is written.
+func AccessLog(logger *zap.Logger) Middleware {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := recordResponse(w)
+			h.ServeHTTP(rw, r)
+			logger.Info("HTTP request",
+				zap.String("request_id", RequestIDOf(r)),
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.Int("status", rw.status),
+				zap.Int("size", rw.size),
+				zap.Duration("duration", time.Since(start)),
+				zap.String("remote", r.RemoteAddr))
+		})
+	}
+}
+
+// Recover turns the panics of the handlers into internal server errors,
+// logging the panic with the stack so that one bad request does not take
+// the connection down without a trace.
+func Recover(logger *zap.Logger) Middleware {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := recordResponse(w)
+			defer func() {
+				err := recover()
+				if err == nil {
+					return
+				}
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+				logger.Error("Panic serving HTTP request",
+					zap.String("request_id", RequestIDOf(r)),
+					zap.String("method", r.Method),
+					zap.String("path", r.URL.Path),
+					zap.String("panic", fmt.Sprint(err)),
+					zap.Stack("stack"))
+				if rw.status == 0 {
+					renderError(rw, r, internalErrorPage())
+				}
+			}()
+			h.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// Metrics reports the count, latency and status of the requests of every
+// route of the mux, tagged with the route pattern the request matched.
+func Metrics(scope tally.Scope, mux *http.ServeMux) Middleware {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, route := mux.Handler(r)
+			if len(route) == 0 {
+				route = "unmatched"
+			}
+			routeScope := scope.Tagged(map[string]string{"route": route})
+
+			start := time.Now()
+			rw := recordResponse(w)
+			h.ServeHTTP(rw, r)
+
+			routeScope.Counter("requests").Inc(1)
+			routeScope.Counter("status_" + strconv.Itoa(rw.status/100) + "xx").Inc(1)
+			routeScope.Timer("latency").Record(time.Since(start))
+		})
+	}
+}
+
+// Gzip compresses the responses of the clients accepting gzip, except for
+// the event streams, which are flushed event by event, and for the bodies
+// that are already compressed.
+func Gzip(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") ||
+			strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+			h.ServeHTTP(w, r)
+			return
+		}
+		gw := &gzipWriter{ResponseWriter: w}
+		defer gw.Close()
+		h.ServeHTTP(gw, r)
+	})
+}
+
+// recordResponse returns w if it already records the response.
+func recordResponse(w http.ResponseWriter) *statusWriter {
+	if rw, ok := w.(*statusWriter); ok {
+		return rw
+	}
+	return &statusWriter{ResponseWriter: w}
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(data)
+	w.size += n
+	return n, err
+}
+
+// Flush lets the event streams flush through the recorder.
+func (w *statusWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets the handlers take over the connection through the recorder.
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, errors.New("hijacking not supported")
+}
+
+func (w *gzipWriter) WriteHeader(status int) {
+	w.decide(status)
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *gzipWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		if len(w.Header().Get("Content-Type")) == 0 {
+			w.Header().Set("Content-Type", http.DetectContentType(data))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.gz == nil {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.gz.Write(data)
+}
+
+// Flush writes the compressed data buffered so far.
+func (w *gzipWriter) Flush() {
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Close completes the compressed body, if any.
+func (w *gzipWriter) Close() error {
+	if w.gz == nil {
+		return nil
+	}
+	return w.gz.Close()
+}
+
+// decide compresses the body of the responses that have one, unless it is
+// encoded already or is an image, which is compressed by its format.
+func (w *gzipWriter) decide(status int) {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	header := w.Header()
+	switch {
+	case status < http.StatusOK, status == http.StatusNoContent,
+		status == http.StatusPartialContent, status == http.StatusNotModified:
+		return
+	case len(header.Get("Content-Encoding")) > 0:
+		return
+	case strings.HasPrefix(header.Get("Content-Type"), "image/"):
+		return
+	}
+	header.Set("Content-Encoding", "gzip")
+	header.Del("Content-Length")
+	w.gz = gzip.NewWriter(w.ResponseWriter)
+}
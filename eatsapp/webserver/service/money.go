@@ -0,0 +1,139 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type (
+	// MoneyFormat formats amounts in a currency with the separators
+	// and symbol position of a locale.
+	MoneyFormat struct {
+		Currency string
+		Symbol   string
+		Decimals int
+		number   numberFormat
+	}
+
+	// currency models how the amounts of a currency are written.
+	currency struct {
+		Symbol   string
+		Decimals int
+	}
+
+	// numberFormat models how a locale writes amounts.
+	numberFormat struct {
+		Decimal     string
+		Group       string
+		SymbolAfter bool // 12,50 € rather than €12.50
+	}
+)
+
+// DefaultCurrency is the currency of the restaurants without one.
+const DefaultCurrency = "USD"
+
+// currencies lists the known currencies by ISO 4217 code, other
+// currencies are written with their code and two decimals.
+var currencies = map[string]currency{
+	"USD": {"$", 2},
+	"CAD": {"CA$", 2},
+	"MXN": {"MX$", 2},
+	"EUR": {"€", 2},
+	"GBP": {"£", 2},
+	"CHF": {"CHF", 2},
+	"INR": {"₹", 2},
+	"JPY": {"¥", 0},
+}
+
+// numberFormats lists the number formats by locale, other
+// locales write amounts as the default locale.
+var numberFormats = map[string]numberFormat{
+	"en": {Decimal: ".", Group: ","},
+	"fr": {Decimal: ",", Group: "\u202f", SymbolAfter: true},
+	"es": {Decimal: ",", Group: ".", SymbolAfter: true},
+}
+
+// NewMoneyFormat returns the format of the amounts in the currency for
+// the locale. An empty currency means the default currency.
+func NewMoneyFormat(locale string, code string) *MoneyFormat {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) == 0 {
+		code = DefaultCurrency
+	}
+	c, ok := currencies[code]
+	if !ok {
+		c = currency{Symbol: code, Decimals: 2}
+	}
+	number, ok := numberFormats[locale]
+	if !ok {
+		number = numberFormats[DefaultLocale]
+	}
+	return &MoneyFormat{
+		Currency: code,
+		Symbol:   c.Symbol,
+		Decimals: c.Decimals,
+		number:   number,
+	}
+}
+
+// Format returns the amount with the currency symbol, rounded to the
+// decimals of the currency, e.g. $1,234.50 or 1 234,50 €. The amount
+// is a float32, as the prices, a float64 or an int.
+func (f *MoneyFormat) Format(amount interface{}) (string, error) {
+	var v float64
+	switch a := amount.(type) {
+	case float32:
+		// format the float32 in decimal first so that 2.675 stays 2.675
+		v, _ = strconv.ParseFloat(strconv.FormatFloat(float64(a), 'f', -1, 32), 64)
+	case float64:
+		v = a
+	case int:
+		v = float64(a)
+	case int64:
+		v = float64(a)
+	default:
+		return "", fmt.Errorf("money: invalid amount %v of type %T", amount, amount)
+	}
+
+	scale := math.Pow10(f.Decimals)
+	units := int64(math.Round(math.Abs(v) * scale))
+	integer := strconv.FormatInt(units/int64(scale), 10)
+	number := groupDigits(integer, f.number.Group)
+	if f.Decimals > 0 {
+		fraction := strconv.FormatInt(units%int64(scale), 10)
+		number += f.number.Decimal + strings.Repeat("0", f.Decimals-len(fraction)) + fraction
+	}
+
+	sign := ""
+	if units > 0 && v < 0 {
+		sign = "-"
+	}
+	if f.number.SymbolAfter {
+		return sign + number + "\u00a0" + f.Symbol, nil
+	}
+	if len(f.Symbol) > 1 && f.Symbol == f.Currency {
+		return sign + f.Symbol + "\u00a0" + number, nil
+	}
+	return sign + f.Symbol + number, nil
+}
+
+// groupDigits separates the thousands of the digits with sep.
+func groupDigits(digits string, sep string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+	var b strings.Builder
+	head := len(digits) % 3
+	if head > 0 {
+		b.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type (
+	// Rating models the aggregated ratings given by customers.
+	Rating struct {
+		Count int
+		Total int
+	}
+)
+
+// Values bounding a single rating.
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// Add adds a single rating to the aggregate.
+func (r *Rating) Add(rating int) {
+	r.Count++
+	r.Total += rating
+}
+
+// Average returns the average rating, or zero if there are no ratings.
+func (r *Rating) Average() float32 {
+	if r == nil || r.Count == 0 {
+		return 0
+	}
+	return float32(r.Total) / float32(r.Count)
+}
+
+// ParseRating parses and validates a single rating value.
+func ParseRating(value string) (int, error) {
+	rating, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if rating < MinRating || rating > MaxRating {
+		return 0, fmt.Errorf("rating must be between %v and %v: %v", MinRating, MaxRating, rating)
+	}
+	return rating, nil
+}
@@ -1,8 +1,9 @@
 package restaurant
 
 import (
-	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"encoding/json"
 	"net/http"
+	"time"
 )
 
 func (h *RestaurantService) addOrder(w http.ResponseWriter, r *http.Request) {
@@ -17,20 +18,35 @@ func (h *RestaurantService) addOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// take the items out of stock, orders with unavailable
+	// items are sent back to the customer for substitution
+	unavailable, err := h.reserveItems(r.Form["item"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(unavailable) > 0 {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(unavailable)
+		return
+	}
+
 	// create order object
 	order := Order{
 		ID:        r.Form.Get("id"),
 		ShortID:   r.Form.Get("id"),
 		TaskToken: []byte(r.Form.Get("task_token")),
 		Status:    OSPending,
+		CreatedAt: time.Now(),
 		ReadySignal: &SignalParam{
 			WorkflowID: r.Form.Get("id"),
 			RunID:      r.Form.Get("run_id"),
 		},
 	}
 	for _, v := range r.Form["item"] {
-		item, err := h.state.menu.GetItemByID(v)
+		item, err := h.menu.GetItemByID(v)
 		if err != nil {
+			h.restockItems(r.Form["item"])
 			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
 			return
 		}
@@ -38,6 +54,14 @@ func (h *RestaurantService) addOrder(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// store order
-	h.state.Orders[order.ID] = &order
-	common.ViewHandler(w, r, &h.state)
+	err = h.putOrder(&order)
+	if err != nil {
+		h.restockItems(r.Form["item"])
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// notify the restaurant, the receipts are recorded on the order
+	h.deliverOrder(&order)
+	h.showOrders(w, r)
 }
@@ -0,0 +1,133 @@
+package restaurant
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+)
+
+type (
+	// OrderResource models a restaurant order returned by the JSON API,
+	// without the workflow task token and signal params.
+	OrderResource struct {
+		ID           string
+		ShortID      string
+		Items        []*common.Item
+		Status       OrderStatus
+		Rating       int
+		Tickets      []*Ticket
+		Changes      *OrderChanges
+		Receipts     []*DeliveryReceipt
+		PrepEstimate time.Duration
+		ReadyBy      time.Time
+		ReadyAt      time.Time
+		CreatedAt    time.Time
+	}
+)
+
+// RegisterAPI adds the menu and restaurant order routes to the API.
+func (h *RestaurantService) RegisterAPI(api *common.API) {
+	api.Handle(&common.Route{
+		Method:   "GET",
+		Path:     "/menu",
+		Summary:  "Lists the menu items",
+		Response: []*common.Item{},
+		Handler: func(r *http.Request, params map[string]string) (interface{}, error) {
+			return h.menu.Snapshot().Items, nil
+		},
+	})
+	api.Handle(&common.Route{
+		Method:   "GET",
+		Path:     "/orders",
+		Summary:  "Lists the restaurant orders, oldest first",
+		Query:    []string{"status"},
+		Response: []*OrderResource{},
+		Roles:    []string{common.RoleStaff},
+		Handler:  h.listOrdersAPI,
+	})
+	api.Handle(&common.Route{
+		Method:   "GET",
+		Path:     "/orders/{id}",
+		Summary:  "Returns a restaurant order",
+		Response: &OrderResource{},
+		Roles:    []string{common.RoleStaff},
+		Handler: func(r *http.Request, params map[string]string) (interface{}, error) {
+			return h.getOrderResource(params["id"])
+		},
+	})
+	api.Handle(&common.Route{
+		Method:   "PATCH",
+		Path:     "/orders/{id}",
+		Summary:  "Applies an action (accept, decline, sent, modify, cancel, rate) to a restaurant order",
+		Query:    []string{"action", "rating"},
+		Response: &OrderResource{},
+		Roles:    []string{common.RoleStaff},
+		Handler:  h.updateOrderAPI,
+	})
+}
+
+func (h *RestaurantService) listOrdersAPI(r *http.Request, params map[string]string) (interface{}, error) {
+	state, err := h.state()
+	if err != nil {
+		return nil, err
+	}
+
+	status := OrderStatus(r.URL.Query().Get("status"))
+	orders := make([]*OrderResource, 0, len(state.Orders))
+	for _, order := range state.Orders {
+		if len(status) > 0 && order.Status != status {
+			continue
+		}
+		orders = append(orders, newOrderResource(order))
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].CreatedAt.Before(orders[j].CreatedAt)
+	})
+	return orders, nil
+}
+
+// updateOrderAPI applies the action through the same handler as
+// the restaurant page and returns the updated order.
+func (h *RestaurantService) updateOrderAPI(r *http.Request, params map[string]string) (interface{}, error) {
+	if !OrdersPolicy.Allows(common.CurrentUser(r), r) {
+		return nil, common.NewAPIError(http.StatusForbidden, "Action not allowed: "+r.URL.Query().Get("action"))
+	}
+	query := r.URL.Query()
+	query.Set("id", params["id"])
+	if _, err := common.Delegate(http.HandlerFunc(h.updateOrder), r, query); err != nil {
+		return nil, err
+	}
+	return h.getOrderResource(params["id"])
+}
+
+func (h *RestaurantService) getOrderResource(orderID string) (*OrderResource, error) {
+	var order Order
+	err := store.GetJSON(h.store, ordersTable, orderID, &order)
+	if err == store.ErrNotFound {
+		return nil, common.NewAPIError(http.StatusNotFound, "Order not found: "+orderID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newOrderResource(&order), nil
+}
+
+func newOrderResource(order *Order) *OrderResource {
+	return &OrderResource{
+		ID:           order.ID,
+		ShortID:      order.ShortID,
+		Items:        order.Items,
+		Status:       order.Status,
+		Rating:       order.Rating,
+		Tickets:      order.Tickets,
+		Changes:      order.Changes,
+		Receipts:     order.Receipts,
+		PrepEstimate: order.PrepEstimate,
+		ReadyBy:      order.ReadyBy,
+		ReadyAt:      order.ReadyAt,
+		CreatedAt:    order.CreatedAt,
+	}
+}
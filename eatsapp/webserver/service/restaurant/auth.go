@@ -0,0 +1,31 @@
+package restaurant
+
+import (
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+)
+
+// Policies authorizing the requests sent to the restaurant services,
+// requests without a role are sent by the workers.
+var (
+	OrdersPolicy = common.Policy{
+		"GET":          {common.RoleStaff},
+		"POST":         nil,
+		"PATCH":        {common.RoleStaff},
+		"PATCH p_sig":  nil,
+		"PATCH cancel": nil,
+		"PATCH rate":   nil,
+	}
+	KitchenPolicy = common.Policy{
+		"GET":   {common.RoleStaff},
+		"POST":  nil,
+		"PATCH": {common.RoleStaff},
+	}
+	// StaffPolicy authorizes the restaurant pages and the lookups of the
+	// workers: capacity, ETA, changes, hours, menu, stock and reports.
+	StaffPolicy = common.Policy{
+		"GET":    {common.RoleStaff},
+		"POST":   {common.RoleStaff},
+		"PATCH":  {common.RoleStaff},
+		"DELETE": {common.RoleStaff},
+	}
+)
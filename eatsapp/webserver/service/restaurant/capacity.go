@@ -0,0 +1,293 @@
+package restaurant
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	config "github.com/venkat1109/cadence-codelab/common"
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+	restaurantwf "github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/restaurant"
+	"go.uber.org/zap"
+)
+
+type (
+	// Capacity models the limits on the orders taken by the restaurant.
+	Capacity struct {
+		MaxPreparing     int
+		MaxOrdersPerHour int
+		Hours            *common.Hours
+		QueueOverflow    bool // queue orders beyond capacity instead of rejecting them
+	}
+
+	// Admission models the decision to accept, queue or reject a new order.
+	Admission struct {
+		Decision string
+		Reason   string
+		Delay    time.Duration
+	}
+
+	// Reservations models the capacity held by the orders admitted but
+	// not placed yet, so that concurrent admissions count each other.
+	Reservations struct {
+		Orders map[string]time.Time // admission time by order ID
+	}
+
+	// Availability models whether the restaurant currently takes orders.
+	Availability struct {
+		Status         string
+		Reason         string
+		Preparing      int
+		OrdersLastHour int
+		OpensAt        time.Time // next opening when closed
+		Scheduled      bool      // orders placed while closed are scheduled
+	}
+
+	// MenuPage models the data displayed on the eats menu page.
+	MenuPage struct {
+		*common.Menu
+		Availability *Availability
+	}
+
+	// CapacityService implements the handlers for requests
+	// sent to the restaurant capacity http service
+	CapacityService struct {
+		restaurant *RestaurantService
+	}
+)
+
+// Values representing the availability of the restaurant.
+const (
+	RSOpen   = "OPEN"
+	RSBusy   = "BUSY"
+	RSClosed = "CLOSED"
+)
+
+const (
+	reservationsTable = "restaurant-reservations"
+	reservationsKey   = "reservations"
+
+	// reservationTimeout releases the capacity held by admitted orders
+	// that were never placed, e.g. because their workflow timed out.
+	reservationTimeout = time.Minute * 20
+)
+
+// NewCapacity returns the capacity described by the restaurant configuration.
+func NewCapacity(cfg config.RestaurantConfig) (*Capacity, error) {
+	hours, err := common.NewHours(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Capacity{
+		MaxPreparing:     cfg.MaxPreparing,
+		MaxOrdersPerHour: cfg.MaxOrdersPerHour,
+		Hours:            hours,
+		QueueOverflow:    cfg.OverCapacity != "reject",
+	}, nil
+}
+
+// NewCapacityService returns a new instance of the CapacityService object.
+func NewCapacityService(restaurant *RestaurantService) *CapacityService {
+	// admissions update the reservations, which must exist beforehand
+	var reservations Reservations
+	err := store.GetJSON(restaurant.store, reservationsTable, reservationsKey, &reservations)
+	if err == store.ErrNotFound {
+		err = store.PutJSON(restaurant.store, reservationsTable, reservationsKey, &reservations)
+	}
+	if err != nil {
+		panic("error loading capacity reservations: " + err.Error())
+	}
+	return &CapacityService{
+		restaurant: restaurant,
+	}
+}
+
+func (h *CapacityService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.admit(w, r)
+	default:
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+}
+
+// admit responds with the admission decision for the new order passed as
+// the "id" param. The capacity taken by accepted and queued orders is
+// reserved in the same update as the decision, until they are placed.
+func (h *CapacityService) admit(w http.ResponseWriter, r *http.Request) {
+	orderID := r.URL.Query().Get("id")
+	state, err := h.restaurant.state()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	intake := h.restaurant.intakeOpen()
+	now := time.Now()
+	var admission *Admission
+	var reservations Reservations
+	err = store.UpdateJSON(h.restaurant.store, reservationsTable, reservationsKey, &reservations, func() error {
+		// a retried admission does not count against itself
+		delete(reservations.Orders, orderID)
+		reservations.hold(state, now)
+
+		admission = h.restaurant.capacity.admit(state, intake, now)
+		switch admission.Decision {
+		case restaurantwf.ADAccept, restaurantwf.ADQueue:
+			reservations.Orders[orderID] = now
+		}
+		return nil
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(admission)
+}
+
+// hold adds the reserved orders to the state as orders being prepared,
+// releasing the reservations of the orders placed since, which the state
+// already counts, and the reservations that timed out.
+func (r *Reservations) hold(state *RestaurantState, now time.Time) {
+	if r.Orders == nil {
+		r.Orders = make(map[string]time.Time)
+	}
+	for id, at := range r.Orders {
+		if _, placed := state.Orders[id]; placed || now.Sub(at) > reservationTimeout {
+			delete(r.Orders, id)
+			continue
+		}
+		state.Orders[id] = &Order{ID: id, Status: OSPreparing, CreatedAt: at}
+	}
+}
+
+// MenuPage returns the menu along with the current availability of the restaurant.
+func (h *RestaurantService) MenuPage() *MenuPage {
+	page := &MenuPage{
+		Menu: h.menu.Snapshot(),
+	}
+	state, err := h.state()
+	if err != nil {
+		common.Logger().Error("Failed to load restaurant state for the menu", zap.Error(err))
+		return page
+	}
+	page.Availability = h.capacity.availability(state, h.intakeOpen(), time.Now())
+	return page
+}
+
+// admit decides whether a new order is accepted, queued until capacity
+// frees up, scheduled for the next opening, or rejected because the
+// restaurant is closed or full. The restaurant is closed outside opening
+// hours and while its order intake is closed.
+func (c *Capacity) admit(state *RestaurantState, intake bool, now time.Time) *Admission {
+	if !intake || !c.Hours.IsOpen(now) {
+		admission := &Admission{Decision: restaurantwf.ADReject, Reason: restaurantwf.ARClosed}
+		if opensAt := c.Hours.NextOpening(now); c.Hours.Schedule && !opensAt.IsZero() {
+			admission.Decision = restaurantwf.ADSchedule
+			admission.Delay = opensAt.Sub(now)
+		}
+		return admission
+	}
+
+	admission := &Admission{Decision: restaurantwf.ADAccept}
+	if c.MaxOrdersPerHour > 0 {
+		recent := state.ordersSince(now.Add(-time.Hour))
+		if len(recent) >= c.MaxOrdersPerHour {
+			// a slot frees up once the oldest order of the hour ages out
+			oldest := recent[0].CreatedAt
+			for _, o := range recent {
+				if o.CreatedAt.Before(oldest) {
+					oldest = o.CreatedAt
+				}
+			}
+			c.overflow(admission, restaurantwf.ARHourlyLimit, oldest.Add(time.Hour).Sub(now))
+		}
+	}
+
+	if c.MaxPreparing > 0 {
+		preparing := state.preparing()
+		if len(preparing) >= c.MaxPreparing {
+			// a slot frees up once the first preparing order is ready
+			var delay time.Duration
+			for i, o := range preparing {
+				d := o.ReadyBy.Sub(now)
+				if o.ReadyBy.IsZero() {
+					d = common.DefaultPrepTime
+				}
+				if i == 0 || d < delay {
+					delay = d
+				}
+			}
+			c.overflow(admission, restaurantwf.ARBusy, delay)
+		}
+	}
+	return admission
+}
+
+// overflow updates the admission of an order beyond capacity, keeping
+// the longest delay when more than one limit was reached.
+func (c *Capacity) overflow(admission *Admission, reason string, delay time.Duration) {
+	if admission.Decision == restaurantwf.ADReject {
+		return
+	}
+	if !c.QueueOverflow {
+		admission.Decision = restaurantwf.ADReject
+		admission.Reason = reason
+		admission.Delay = 0
+		return
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	admission.Decision = restaurantwf.ADQueue
+	if delay >= admission.Delay {
+		admission.Reason = reason
+		admission.Delay = delay
+	}
+}
+
+// availability returns whether the restaurant currently takes orders.
+func (c *Capacity) availability(state *RestaurantState, intake bool, now time.Time) *Availability {
+	a := &Availability{
+		Status:         RSOpen,
+		Preparing:      len(state.preparing()),
+		OrdersLastHour: len(state.ordersSince(now.Add(-time.Hour))),
+	}
+	admission := c.admit(state, intake, now)
+	switch {
+	case admission.Reason == restaurantwf.ARClosed:
+		a.Status = RSClosed
+		a.Reason = admission.Reason
+		a.OpensAt = c.Hours.NextOpening(now)
+		a.Scheduled = admission.Decision == restaurantwf.ADSchedule
+	case admission.Decision != restaurantwf.ADAccept:
+		a.Status = RSBusy
+		a.Reason = admission.Reason
+	}
+	return a
+}
+
+// ordersSince returns the orders placed after the specified time,
+// not counting orders that were rejected.
+func (s *RestaurantState) ordersSince(t time.Time) []*Order {
+	var orders []*Order
+	for _, o := range s.Orders {
+		if o.Status != OSRejected && o.CreatedAt.After(t) {
+			orders = append(orders, o)
+		}
+	}
+	return orders
+}
+
+// preparing returns the orders currently being prepared.
+func (s *RestaurantState) preparing() []*Order {
+	var orders []*Order
+	for _, o := range s.Orders {
+		if o.Status == OSPreparing {
+			orders = append(orders, o)
+		}
+	}
+	return orders
+}
@@ -0,0 +1,129 @@
+package restaurant
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	config "github.com/venkat1109/cadence-codelab/common"
+	restaurantwf "github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/restaurant"
+)
+
+func TestCapacityAdmit(t *testing.T) {
+	// a Monday, at 12:00 UTC
+	now := time.Date(2017, 11, 20, 12, 0, 0, 0, time.UTC)
+	daily := func(interval string) map[string]string {
+		hours := make(map[string]string)
+		for _, day := range []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"} {
+			hours[day] = interval
+		}
+		return hours
+	}
+	preparing := func(n int, readyIn time.Duration) *RestaurantState {
+		state := &RestaurantState{Orders: make(map[string]*Order)}
+		for i := 0; i < n; i++ {
+			id := fmt.Sprintf("o%d", i)
+			state.Orders[id] = &Order{
+				ID:        id,
+				Status:    OSPreparing,
+				CreatedAt: now.Add(-time.Minute * time.Duration(10*(i+1))),
+				ReadyBy:   now.Add(readyIn + time.Minute*time.Duration(i)),
+			}
+		}
+		return state
+	}
+
+	tests := []struct {
+		name     string
+		cfg      config.RestaurantConfig
+		state    *RestaurantState
+		intake   bool
+		decision string
+		reason   string
+		delay    time.Duration
+	}{
+		{
+			name:     "always open",
+			state:    preparing(3, time.Minute),
+			intake:   true,
+			decision: restaurantwf.ADAccept,
+		},
+		{
+			name:     "intake closed",
+			state:    preparing(0, 0),
+			decision: restaurantwf.ADReject,
+			reason:   restaurantwf.ARClosed,
+		},
+		{
+			name:     "outside opening hours",
+			cfg:      config.RestaurantConfig{TimeZone: "UTC", Hours: daily("18:00-23:00")},
+			state:    preparing(0, 0),
+			intake:   true,
+			decision: restaurantwf.ADReject,
+			reason:   restaurantwf.ARClosed,
+		},
+		{
+			name:     "scheduled for the next opening",
+			cfg:      config.RestaurantConfig{TimeZone: "UTC", Hours: daily("18:00-23:00"), AfterHours: "schedule"},
+			state:    preparing(0, 0),
+			intake:   true,
+			decision: restaurantwf.ADSchedule,
+			reason:   restaurantwf.ARClosed,
+			delay:    6 * time.Hour,
+		},
+		{
+			name:     "below capacity",
+			cfg:      config.RestaurantConfig{MaxPreparing: 3},
+			state:    preparing(2, time.Minute),
+			intake:   true,
+			decision: restaurantwf.ADAccept,
+		},
+		{
+			name:     "queued until the first order is ready",
+			cfg:      config.RestaurantConfig{MaxPreparing: 2},
+			state:    preparing(2, 5*time.Minute),
+			intake:   true,
+			decision: restaurantwf.ADQueue,
+			reason:   restaurantwf.ARBusy,
+			delay:    5 * time.Minute,
+		},
+		{
+			name:     "rejected over capacity",
+			cfg:      config.RestaurantConfig{MaxPreparing: 2, OverCapacity: "reject"},
+			state:    preparing(2, 5*time.Minute),
+			intake:   true,
+			decision: restaurantwf.ADReject,
+			reason:   restaurantwf.ARBusy,
+		},
+		{
+			name:     "hourly limit waits for the oldest order",
+			cfg:      config.RestaurantConfig{MaxOrdersPerHour: 2},
+			state:    preparing(2, 5*time.Minute),
+			intake:   true,
+			decision: restaurantwf.ADQueue,
+			reason:   restaurantwf.ARHourlyLimit,
+			delay:    40 * time.Minute,
+		},
+		{
+			name:     "longest delay of both limits",
+			cfg:      config.RestaurantConfig{MaxPreparing: 2, MaxOrdersPerHour: 2},
+			state:    preparing(2, 50*time.Minute),
+			intake:   true,
+			decision: restaurantwf.ADQueue,
+			reason:   restaurantwf.ARBusy,
+			delay:    50 * time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capacity, err := NewCapacity(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			admission := capacity.admit(tt.state, tt.intake, now)
+			if admission.Decision != tt.decision || admission.Reason != tt.reason || admission.Delay != tt.delay {
+				t.Errorf("admission = %+v, want %s %s after %v", admission, tt.decision, tt.reason, tt.delay)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package restaurant
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+	restaurantwf "github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/restaurant"
+)
+
+type (
+	// ChangesService implements the handlers for requests sent to the
+	// restaurant changes http service, which returns the changes made
+	// to the orders that were only partially accepted.
+	ChangesService struct {
+		restaurant *RestaurantService
+	}
+
+	// OrderChanges models the items rejected or substituted by the
+	// restaurant when accepting an order.
+	OrderChanges struct {
+		OrderID       string
+		Items         []string
+		Rejected      []string
+		Substitutions map[string]string
+		OriginalTotal float32
+		Total         float32
+	}
+)
+
+// Values for the per item choices of a partially accepted order,
+// any other value is the ID of the substitute item.
+const (
+	keepItem   = "keep"
+	rejectItem = "reject"
+)
+
+var (
+	errNoChanges     = errors.New("order is unchanged, accept it instead")
+	errNothingLeft   = errors.New("all items are rejected, decline the order instead")
+	errOrderModified = errors.New("order was modified concurrently")
+)
+
+// NewChangesService returns a new instance of the ChangesService object.
+func NewChangesService(restaurant *RestaurantService) *ChangesService {
+	return &ChangesService{
+		restaurant: restaurant,
+	}
+}
+
+func (h *ChangesService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.showChanges(w, r)
+	default:
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *ChangesService) showChanges(w http.ResponseWriter, r *http.Request) {
+	orderID := r.URL.Query().Get("id")
+
+	var order Order
+	err := store.GetJSON(h.restaurant.store, ordersTable, orderID, &order)
+	if err == store.ErrNotFound || (err == nil && order.Changes == nil) {
+		http.Error(w, "Order changes not found: "+orderID, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(order.Changes)
+}
+
+// modifyItems accepts the pending order with some of its items rejected
+// or substituted. The "item" form values hold one choice per order item,
+// in order: "keep", "reject" or the ID of the substitute item.
+//
+// The changes are stored before the activity is completed, so that the
+// workflow always finds them, and the order is prepared once it is. A
+// failed completion is retried by modifying the order again, which
+// completes the activity with the stored changes.
+func (h *RestaurantService) modifyItems(w http.ResponseWriter, r *http.Request, orderID string) {
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	order, record, err := h.readOrder(orderID)
+	if err == store.ErrNotFound {
+		http.Error(w, "Order not found: "+orderID, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if order.Status != OSPending {
+		http.Error(w, errOrderNotPending.Error(), http.StatusConflict)
+		return
+	}
+
+	if order.Changes == nil {
+		changes, err := h.orderChanges(order, r.Form["item"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		// substitutes come out of stock like the items of a new order
+		substitutes := make([]string, 0, len(changes.Substitutions))
+		for _, id := range changes.Substitutions {
+			substitutes = append(substitutes, id)
+		}
+		unavailable, err := h.reserveItems(substitutes)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if len(unavailable) > 0 {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(unavailable)
+			return
+		}
+
+		order.Changes = changes
+		err = h.saveOrder(order, record)
+		if err != nil {
+			h.restockItems(substitutes)
+		}
+		if err == store.ErrConflict {
+			http.Error(w, errOrderModified.Error(), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// the activity is completed without holding the store
+	if err := h.client.CompleteActivity(order.TaskToken, restaurantwf.OrderModified, nil); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	prepared := false
+	order, err = h.modifyOrder(orderID, func(order *Order) error {
+		if order.Status != OSPending || order.Changes == nil {
+			return nil
+		}
+		items, err := h.changedItems(order.Changes)
+		if err != nil {
+			return err
+		}
+		order.Items = items
+		order.Status = OSPreparing
+		prepared = true
+		return nil
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// rejected and replaced items are not prepared
+	if prepared {
+		h.restockItems(replaced(order.Changes))
+	}
+	fmt.Fprintf(w, "%+v", order)
+}
+
+// changedItems returns the items the restaurant prepares for the changes.
+func (h *RestaurantService) changedItems(changes *OrderChanges) ([]*common.Item, error) {
+	items := make([]*common.Item, 0, len(changes.Items))
+	for _, id := range changes.Items {
+		item, err := h.menu.GetItemByID(id)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// orderChanges applies the per item choices to the order, returning the
+// changes, which list the items the restaurant will prepare.
+func (h *RestaurantService) orderChanges(order *Order, choices []string) (*OrderChanges, error) {
+	if len(choices) != len(order.Items) {
+		return nil, fmt.Errorf("expected %d item choices, got %d", len(order.Items), len(choices))
+	}
+
+	changes := &OrderChanges{
+		OrderID:       order.ID,
+		Substitutions: make(map[string]string),
+	}
+	var items []*common.Item
+	for i, item := range order.Items {
+		changes.OriginalTotal += item.Price
+
+		switch choices[i] {
+		case keepItem:
+			items = append(items, item)
+		case rejectItem:
+			changes.Rejected = append(changes.Rejected, item.ID)
+			continue
+		default:
+			substitute, err := h.menu.GetItemByID(choices[i])
+			if err != nil {
+				return nil, err
+			}
+			if substitute.ID != item.ID {
+				changes.Substitutions[item.ID] = substitute.ID
+			}
+			items = append(items, substitute)
+		}
+		changes.Items = append(changes.Items, items[len(items)-1].ID)
+		changes.Total += items[len(items)-1].Price
+	}
+
+	if len(items) == 0 {
+		return nil, errNothingLeft
+	}
+	if len(changes.Rejected) == 0 && len(changes.Substitutions) == 0 {
+		return nil, errNoChanges
+	}
+	return changes, nil
+}
+
+// cancelOrder cancels an order the customer no longer wants after the
+// restaurant modified it, putting its items back in stock.
+func (h *RestaurantService) cancelOrder(w http.ResponseWriter, r *http.Request, orderID string) {
+	var prevStatus OrderStatus
+	order, err := h.modifyOrder(orderID, func(order *Order) error {
+		prevStatus = order.Status
+		if order.Status == OSPending || order.Status == OSPreparing {
+			order.Status = OSRejected
+		}
+		return nil
+	})
+	if err == store.ErrNotFound {
+		http.Error(w, "Order not found: "+orderID, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if order.Status == OSRejected && prevStatus != OSRejected {
+		h.restockItems(order.itemIDs())
+	}
+	fmt.Fprintf(w, "%+v", order)
+}
+
+// replaced returns the IDs of the items taken out of the order.
+func replaced(changes *OrderChanges) []string {
+	ids := append([]string{}, changes.Rejected...)
+	for id := range changes.Substitutions {
+		ids = append(ids, id)
+	}
+	return ids
+}
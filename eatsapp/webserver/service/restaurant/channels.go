@@ -0,0 +1,320 @@
+package restaurant
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	config "github.com/venkat1109/cadence-codelab/common"
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"go.uber.org/zap"
+)
+
+type (
+	// Channel delivers new orders to the restaurant, e.g. to its
+	// point of sale system or to a kitchen printer.
+	Channel interface {
+		// Name identifies the channel on delivery receipts.
+		Name() string
+		// Deliver sends the order, it is retried on error.
+		Deliver(order *Order) error
+	}
+
+	// DeliveryReceipt records the delivery of an order through a channel.
+	DeliveryReceipt struct {
+		Channel     string
+		Status      DeliveryStatus
+		Attempts    int
+		LastError   string
+		DeliveredAt time.Time
+	}
+
+	// DeliveryStatus is the type that represents the status of a delivery receipt
+	DeliveryStatus string
+
+	// OrderPayload models the order sent to the restaurant webhook.
+	OrderPayload struct {
+		ID        string
+		Items     []OrderPayloadItem
+		Total     float32
+		CreatedAt time.Time
+		ActionURL string // PATCH with action=accept or action=decline
+	}
+
+	// OrderPayloadItem models an item of the order sent to the restaurant webhook.
+	OrderPayloadItem struct {
+		ID      string
+		Name    string
+		Price   float32
+		Station string
+	}
+
+	dashboardChannel struct{}
+
+	webhookChannel struct {
+		url    string
+		secret []byte
+		client *http.Client
+	}
+
+	printerChannel struct {
+		spoolDir string
+	}
+
+	// retryingChannel retries the deliveries that failed with backoff.
+	retryingChannel struct {
+		Channel
+		maxAttempts int
+	}
+)
+
+// Values representing delivery status.
+const (
+	DSPending   DeliveryStatus = "PENDING"
+	DSDelivered                = "DELIVERED"
+	DSFailed                   = "FAILED"
+)
+
+const (
+	// SignatureHeader holds the hex HMAC-SHA256 of the webhook payload,
+	// keyed with the secret shared with the restaurant.
+	SignatureHeader = "X-Bistro-Signature"
+
+	defaultMaxAttempts = 5
+	initialBackoff     = time.Second
+	maxBackoff         = time.Minute
+	webhookTimeout     = time.Second * 10
+	actionURL          = "http://localhost:8090/restaurant?id="
+)
+
+// NewChannel returns the channel described by the configuration.
+func NewChannel(cfg config.ChannelConfig) (Channel, error) {
+	var channel Channel
+	switch cfg.Type {
+	case "dashboard":
+		channel = dashboardChannel{}
+	case "webhook":
+		if len(cfg.URL) == 0 {
+			return nil, errors.New("webhook channel requires an url")
+		}
+		channel = &webhookChannel{
+			url:    cfg.URL,
+			secret: []byte(cfg.Secret),
+			client: &http.Client{Timeout: webhookTimeout},
+		}
+	case "printer":
+		if len(cfg.SpoolDir) == 0 {
+			return nil, errors.New("printer channel requires a spool_dir")
+		}
+		if err := os.MkdirAll(cfg.SpoolDir, 0755); err != nil {
+			return nil, err
+		}
+		channel = &printerChannel{spoolDir: cfg.SpoolDir}
+	default:
+		return nil, errors.New("unknown order channel: " + cfg.Type)
+	}
+
+	return channel, nil
+}
+
+func newChannels(cfgs []config.ChannelConfig) ([]*retryingChannel, error) {
+	if len(cfgs) == 0 {
+		cfgs = []config.ChannelConfig{{Type: "dashboard"}}
+	}
+	channels := make([]*retryingChannel, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		channel, err := NewChannel(cfg)
+		if err != nil {
+			return nil, err
+		}
+		maxAttempts := cfg.MaxAttempts
+		if maxAttempts <= 0 {
+			maxAttempts = defaultMaxAttempts
+		}
+		channels = append(channels, &retryingChannel{Channel: channel, maxAttempts: maxAttempts})
+	}
+	return channels, nil
+}
+
+// deliverOrder sends a new order through every channel, recording
+// a delivery receipt on the order after each attempt.
+func (h *RestaurantService) deliverOrder(order *Order) {
+	for _, channel := range h.channels {
+		go func(channel *retryingChannel) {
+			channel.deliver(order, func(receipt *DeliveryReceipt) {
+				_, err := h.modifyOrder(order.ID, func(order *Order) error {
+					order.setReceipt(receipt)
+					return nil
+				})
+				if err != nil {
+					common.Logger().Error("Failed to record delivery receipt", zap.String("order", order.ID), zap.Error(err))
+				}
+			})
+		}(channel)
+	}
+}
+
+// deliver attempts the delivery until it succeeds or runs out of
+// attempts, calling record with the receipt after every attempt.
+func (c *retryingChannel) deliver(order *Order, record func(receipt *DeliveryReceipt)) {
+	receipt := &DeliveryReceipt{
+		Channel: c.Name(),
+		Status:  DSPending,
+	}
+	backoff := initialBackoff
+	for receipt.Attempts < c.maxAttempts {
+		receipt.Attempts++
+		err := c.Deliver(order)
+		if err == nil {
+			receipt.Status = DSDelivered
+			receipt.LastError = ""
+			receipt.DeliveredAt = time.Now()
+			record(receipt)
+			return
+		}
+
+		receipt.LastError = err.Error()
+		if receipt.Attempts == c.maxAttempts {
+			receipt.Status = DSFailed
+		}
+		record(receipt)
+
+		if receipt.Status != DSFailed {
+			time.Sleep(backoff)
+			if backoff *= 2; backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+	}
+}
+
+func (dashboardChannel) Name() string {
+	return "dashboard"
+}
+
+// Deliver is a no-op, stored orders are listed on the /restaurant page.
+func (dashboardChannel) Deliver(order *Order) error {
+	return nil
+}
+
+func (c *webhookChannel) Name() string {
+	return "webhook"
+}
+
+// Deliver posts the order as JSON to the restaurant point of sale,
+// signed with the shared secret so that the restaurant can verify it.
+func (c *webhookChannel) Deliver(order *Order) error {
+	data, err := json.Marshal(newOrderPayload(order))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", c.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(c.secret, data))
+
+	rsp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 300 {
+		return errors.New("webhook responded " + rsp.Status)
+	}
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of the payload keyed with the secret.
+func Sign(secret []byte, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (c *printerChannel) Name() string {
+	return "printer"
+}
+
+// Deliver writes the order ticket to the spool directory, where it is
+// picked up by the kitchen printer. The ticket is written to a temporary
+// file first so that the printer never sees a partial ticket.
+func (c *printerChannel) Deliver(order *Order) error {
+	path := filepath.Join(c.spoolDir, order.ID+".txt")
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, []byte(renderTicket(order)), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// renderTicket renders the order as a plain text printer ticket.
+func renderTicket(order *Order) string {
+	const width = 32
+	rule := strings.Repeat("-", width) + "\n"
+
+	var b bytes.Buffer
+	b.WriteString("CADENCE BISTRO\n")
+	fmt.Fprintf(&b, "ORDER %s\n", order.ShortID)
+	fmt.Fprintf(&b, "%s\n", order.CreatedAt.Format("2006-01-02 15:04:05"))
+	b.WriteString(rule)
+
+	var total float32
+	for _, item := range order.Items {
+		fmt.Fprintf(&b, "%-*s%8.2f\n", width-8, truncate(item.Name, width-9), item.Price)
+		fmt.Fprintf(&b, "  [%s]\n", item.KitchenStation())
+		total += item.Price
+	}
+	b.WriteString(rule)
+	fmt.Fprintf(&b, "%-*s%8.2f\n", width-8, "TOTAL", total)
+	return b.String()
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+func newOrderPayload(order *Order) *OrderPayload {
+	payload := &OrderPayload{
+		ID:        order.ID,
+		CreatedAt: order.CreatedAt,
+		ActionURL: actionURL + order.ID,
+	}
+	for _, item := range order.Items {
+		payload.Items = append(payload.Items, OrderPayloadItem{
+			ID:      item.ID,
+			Name:    item.Name,
+			Price:   item.Price,
+			Station: item.KitchenStation(),
+		})
+		payload.Total += item.Price
+	}
+	return payload
+}
+
+// setReceipt replaces the receipt of the channel on the order.
+func (o *Order) setReceipt(receipt *DeliveryReceipt) {
+	for i, r := range o.Receipts {
+		if r.Channel == receipt.Channel {
+			o.Receipts[i] = receipt
+			return
+		}
+	}
+	o.Receipts = append(o.Receipts, receipt)
+}
@@ -0,0 +1,344 @@
+package restaurant
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/message"
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+	"go.uber.org/zap"
+)
+
+type (
+	// ETAService implements the handlers for requests
+	// sent to the restaurant ETA http service
+	ETAService struct {
+		restaurant *RestaurantService
+	}
+
+	// Estimate models the time at which an order is expected to be ready.
+	Estimate struct {
+		OrderID     string
+		ETA         time.Duration
+		ReadyAt     time.Time
+		QueueLength int     // number of preparing orders ahead of this one
+		Factor      float64 // correction learnt from actual prep times
+	}
+
+	// PrepRecord models the actual prep time of an order,
+	// recorded when the order moves to READY.
+	PrepRecord struct {
+		OrderID   string
+		Estimated time.Duration
+		Actual    time.Duration
+		ReadyAt   time.Time
+	}
+)
+
+const (
+	prepTimesTable = "restaurant-prep-times"
+
+	// prepHistorySize is the number of recent orders used to correct estimates.
+	prepHistorySize = 50
+	// maxETADrift is how far an estimate may move before it is re-published.
+	maxETADrift = time.Minute * 2
+
+	minPrepFactor = 0.5
+	maxPrepFactor = 3.0
+)
+
+// NewETAService returns a new instance of the ETAService object.
+func NewETAService(restaurant *RestaurantService) *ETAService {
+	return &ETAService{
+		restaurant: restaurant,
+	}
+}
+
+func (h *ETAService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.estimate(w, r)
+	default:
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+}
+
+// estimate responds with the ETA of the order passed as the "id" param
+// and publishes it on the order, so that later drifts can be detected.
+func (h *ETAService) estimate(w http.ResponseWriter, r *http.Request) {
+	orderID := r.URL.Query().Get("id")
+	est, err := h.restaurant.estimate(orderID, time.Now())
+	if err == store.ErrNotFound {
+		http.Error(w, "Order not found: "+orderID, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_, err = h.restaurant.modifyOrder(orderID, func(order *Order) error {
+		order.ReadyBy = est.ReadyAt
+		if order.PrepEstimate == 0 {
+			order.PrepEstimate = est.ETA
+		}
+		return nil
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(est)
+}
+
+// estimate computes the ETA of an order. Every station works through its
+// tickets in order, so the order is ready once the slowest of its stations
+// cleared the tickets ahead of it and its own ticket. The result is
+// corrected by how long orders actually took compared to their estimates.
+func (h *RestaurantService) estimate(orderID string, now time.Time) (*Estimate, error) {
+	state, err := h.state()
+	if err != nil {
+		return nil, err
+	}
+	order, ok := state.Orders[orderID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+
+	var queueLength int
+	backlog := make(map[string]time.Duration)
+	for _, other := range state.Orders {
+		if other.ID == orderID || other.Status != OSPreparing || !other.aheadOf(order) {
+			continue
+		}
+		queueLength++
+		for station, d := range other.remainingPrep() {
+			backlog[station] += d
+		}
+	}
+
+	factor, err := h.prepFactor()
+	if err != nil {
+		return nil, err
+	}
+	return newEstimate(order, backlog, queueLength, factor, now), nil
+}
+
+// estimates computes the ETA of every preparing order in a single walk
+// of the queue, rather than once per order: the orders are visited in
+// the order the kitchen works through them, growing the backlog of the
+// stations as they go.
+func (h *RestaurantService) estimates(state *RestaurantState, now time.Time) (map[string]*Estimate, error) {
+	factor, err := h.prepFactor()
+	if err != nil {
+		return nil, err
+	}
+
+	queue := make([]*Order, 0, len(state.Orders))
+	for _, order := range state.Orders {
+		if order.Status == OSPreparing {
+			queue = append(queue, order)
+		}
+	}
+	sort.Slice(queue, func(i, j int) bool {
+		return queue[i].aheadOf(queue[j])
+	})
+
+	ests := make(map[string]*Estimate, len(queue))
+	backlog := make(map[string]time.Duration)
+	for i := 0; i < len(queue); {
+		// orders that reached the kitchen together are not ahead of each other
+		j := i + 1
+		for j < len(queue) && !queue[i].aheadOf(queue[j]) {
+			j++
+		}
+		for _, order := range queue[i:j] {
+			ests[order.ID] = newEstimate(order, backlog, i, factor, now)
+		}
+		for _, order := range queue[i:j] {
+			for station, d := range order.remainingPrep() {
+				backlog[station] += d
+			}
+		}
+		i = j
+	}
+	return ests, nil
+}
+
+// newEstimate returns the estimate of an order behind the backlog of
+// the stations, corrected by the prep factor.
+func newEstimate(order *Order, backlog map[string]time.Duration, queueLength int, factor float64, now time.Time) *Estimate {
+	var raw time.Duration
+	for station, d := range order.remainingPrep() {
+		if d+backlog[station] > raw {
+			raw = d + backlog[station]
+		}
+	}
+	eta := time.Duration(float64(raw)*factor) / time.Second * time.Second
+	return &Estimate{
+		OrderID:     order.ID,
+		ETA:         eta,
+		ReadyAt:     now.Add(eta),
+		QueueLength: queueLength,
+		Factor:      factor,
+	}
+}
+
+// prepFactor returns the ratio of actual to estimated prep
+// time over the most recent orders that became ready.
+func (h *RestaurantService) prepFactor() (float64, error) {
+	values, err := h.store.List(prepTimesTable)
+	if err != nil {
+		return 0, err
+	}
+
+	records := make([]*PrepRecord, 0, len(values))
+	for _, v := range values {
+		var rec PrepRecord
+		if err := json.Unmarshal(v, &rec); err != nil {
+			return 0, err
+		}
+		records = append(records, &rec)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].ReadyAt.After(records[j].ReadyAt)
+	})
+	if len(records) > prepHistorySize {
+		records = records[:prepHistorySize]
+	}
+
+	var estimated, actual time.Duration
+	for _, rec := range records {
+		estimated += rec.Estimated
+		actual += rec.Actual
+	}
+	if estimated <= 0 {
+		return 1, nil
+	}
+
+	factor := float64(actual) / float64(estimated)
+	if factor < minPrepFactor {
+		factor = minPrepFactor
+	}
+	if factor > maxPrepFactor {
+		factor = maxPrepFactor
+	}
+	return factor, nil
+}
+
+// recordPrepTime stores the actual prep time of an order that became ready.
+func (h *RestaurantService) recordPrepTime(order *Order) error {
+	actual, ok := order.prepTime()
+	if order.PrepEstimate == 0 || !ok {
+		return nil
+	}
+	rec := PrepRecord{
+		OrderID:   order.ID,
+		Estimated: order.PrepEstimate,
+		Actual:    actual,
+		ReadyAt:   order.ReadyAt,
+	}
+	return store.PutJSON(h.store, prepTimesTable, order.ID, &rec)
+}
+
+// scheduleETAs republishes the ETAs in the background, so that the
+// request changing the queue does not wait for the signals. Requests
+// made while the ETAs are republished are coalesced into one more run.
+func (h *RestaurantService) scheduleETAs() {
+	h.etaLock.Lock()
+	defer h.etaLock.Unlock()
+	if h.etaRunning {
+		h.etaPending = true
+		return
+	}
+	h.etaRunning = true
+	go func() {
+		for {
+			h.republishETAs()
+
+			h.etaLock.Lock()
+			if !h.etaPending {
+				h.etaRunning = false
+				h.etaLock.Unlock()
+				return
+			}
+			h.etaPending = false
+			h.etaLock.Unlock()
+		}
+	}()
+}
+
+// republishETAs re-estimates every preparing order and signals the revised
+// ETA to the eats.OrderWorkflow of the orders whose estimate drifted.
+func (h *RestaurantService) republishETAs() {
+	state, err := h.state()
+	if err != nil {
+		common.Logger().Error("Failed to list orders for ETA updates", zap.Error(err))
+		return
+	}
+	ests, err := h.estimates(state, time.Now())
+	if err != nil {
+		common.Logger().Error("Failed to estimate order ETAs", zap.Error(err))
+		return
+	}
+
+	for id, order := range state.Orders {
+		est, ok := ests[id]
+		if !ok || order.ReadyBy.IsZero() {
+			continue
+		}
+		drift := est.ReadyAt.Sub(order.ReadyBy)
+		if drift < maxETADrift && drift > -maxETADrift {
+			continue
+		}
+
+		update := message.ETAUpdate{
+			OrderID: id,
+			ReadyAt: est.ReadyAt,
+		}
+		err = h.client.SignalWorkflow(order.ReadySignal.WorkflowID, order.ReadySignal.RunID, message.ETASignalName, update)
+		if err != nil {
+			common.Logger().Error("Failed to signal order ETA", zap.String("order", id), zap.Error(err))
+			continue
+		}
+		h.modifyOrder(id, func(order *Order) error {
+			order.ReadyBy = est.ReadyAt
+			return nil
+		})
+	}
+}
+
+// aheadOf returns true if the order reached the kitchen before the other order.
+func (o *Order) aheadOf(other *Order) bool {
+	if len(o.Tickets) == 0 {
+		return false
+	}
+	if len(other.Tickets) == 0 {
+		return true
+	}
+	return o.Tickets[0].CreatedAt.Before(other.Tickets[0].CreatedAt)
+}
+
+// remainingPrep returns the prep time left at each station for the order.
+func (o *Order) remainingPrep() map[string]time.Duration {
+	prep := make(map[string]time.Duration)
+	if len(o.Tickets) == 0 {
+		for _, item := range o.Items {
+			prep[item.KitchenStation()] += item.PrepTimeOrDefault()
+		}
+		return prep
+	}
+	for _, t := range o.Tickets {
+		if t.Status == TSDone {
+			continue
+		}
+		for _, item := range t.Items {
+			prep[t.Station] += item.PrepTimeOrDefault()
+		}
+	}
+	return prep
+}
@@ -0,0 +1,108 @@
+package restaurant
+
+import (
+	"testing"
+	"time"
+
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+)
+
+func TestEstimates(t *testing.T) {
+	now := time.Date(2017, 11, 20, 12, 0, 0, 0, time.UTC)
+	item := func(station string, minutes int) *common.Item {
+		return &common.Item{Station: station, PrepTime: time.Minute * time.Duration(minutes)}
+	}
+	ticket := func(station string, status TicketStatus, minutesAgo int, items ...*common.Item) *Ticket {
+		return &Ticket{Station: station, Status: status, CreatedAt: now.Add(-time.Minute * time.Duration(minutesAgo)), Items: items}
+	}
+
+	tests := []struct {
+		name   string
+		orders []*Order
+		want   map[string]time.Duration // ETA by order, preparing orders only
+		queue  map[string]int
+	}{
+		{
+			name: "shared station",
+			orders: []*Order{
+				{ID: "o1", Status: OSPreparing, Tickets: []*Ticket{ticket("grill", TSOpen, 10, item("grill", 5))}},
+				{ID: "o2", Status: OSPreparing, Tickets: []*Ticket{ticket("grill", TSOpen, 5, item("grill", 3))}},
+			},
+			want:  map[string]time.Duration{"o1": 5 * time.Minute, "o2": 8 * time.Minute},
+			queue: map[string]int{"o1": 0, "o2": 1},
+		},
+		{
+			name: "slowest station",
+			orders: []*Order{
+				{ID: "o1", Status: OSPreparing, Tickets: []*Ticket{ticket("grill", TSOpen, 10, item("grill", 10))}},
+				{ID: "o2", Status: OSPreparing, Tickets: []*Ticket{
+					ticket("grill", TSOpen, 5, item("grill", 2)),
+					ticket("fryer", TSOpen, 5, item("fryer", 4)),
+				}},
+			},
+			want:  map[string]time.Duration{"o1": 10 * time.Minute, "o2": 12 * time.Minute},
+			queue: map[string]int{"o1": 0, "o2": 1},
+		},
+		{
+			name: "done tickets and other orders",
+			orders: []*Order{
+				{ID: "o1", Status: OSPreparing, Tickets: []*Ticket{ticket("grill", TSDone, 10, item("grill", 5))}},
+				{ID: "o2", Status: OSReady, Tickets: []*Ticket{ticket("grill", TSOpen, 8, item("grill", 5))}},
+				{ID: "o3", Status: OSPreparing, Tickets: []*Ticket{ticket("grill", TSOpen, 5, item("grill", 3))}},
+			},
+			want:  map[string]time.Duration{"o1": 0, "o3": 3 * time.Minute},
+			queue: map[string]int{"o1": 0, "o3": 1},
+		},
+		{
+			name: "orders reaching the kitchen together",
+			orders: []*Order{
+				{ID: "o1", Status: OSPreparing, Tickets: []*Ticket{ticket("grill", TSOpen, 5, item("grill", 5))}},
+				{ID: "o2", Status: OSPreparing, Tickets: []*Ticket{ticket("grill", TSOpen, 5, item("grill", 3))}},
+				{ID: "o3", Status: OSPreparing, Items: []*common.Item{item("grill", 1)}},
+			},
+			want:  map[string]time.Duration{"o1": 5 * time.Minute, "o2": 3 * time.Minute, "o3": 9 * time.Minute},
+			queue: map[string]int{"o1": 0, "o2": 0, "o3": 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &RestaurantService{store: store.NewMemoryStore()}
+			for _, order := range tt.orders {
+				if err := h.putOrder(order); err != nil {
+					t.Fatal(err)
+				}
+			}
+			state, err := h.state()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ests, err := h.estimates(state, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ests) != len(tt.want) {
+				t.Errorf("got %d estimates, want %d", len(ests), len(tt.want))
+			}
+			for id, eta := range tt.want {
+				est, ok := ests[id]
+				if !ok {
+					t.Errorf("no estimate for %s", id)
+					continue
+				}
+				if est.ETA != eta || est.QueueLength != tt.queue[id] || !est.ReadyAt.Equal(now.Add(eta)) {
+					t.Errorf("estimate of %s = %+v, want %v behind %d orders", id, est, eta, tt.queue[id])
+				}
+				// the single order estimate must agree with the walk
+				single, err := h.estimate(id, now)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if *single != *est {
+					t.Errorf("estimate(%s) = %+v, estimates() = %+v", id, single, est)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package restaurant
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+	"go.uber.org/zap"
+)
+
+type (
+	// HoursService implements the handlers for requests sent to the
+	// restaurant hours http service, used by the daily hours workflow
+	// to open and close the order intake of the restaurant.
+	HoursService struct {
+		restaurant *RestaurantService
+	}
+
+	// OpeningHours models the current, or next, opening interval
+	// of the restaurant and the state of its order intake.
+	OpeningHours struct {
+		Intake     bool
+		AlwaysOpen bool
+		OpensAt    time.Time
+		ClosesAt   time.Time
+	}
+
+	// Intake models whether the restaurant takes new orders.
+	Intake struct {
+		Open      bool
+		UpdatedAt time.Time
+	}
+)
+
+const (
+	intakeTable = "restaurant-intake"
+	intakeKey   = "intake"
+)
+
+// NewHoursService returns a new instance of the HoursService object.
+func NewHoursService(restaurant *RestaurantService) *HoursService {
+	return &HoursService{
+		restaurant: restaurant,
+	}
+}
+
+func (h *HoursService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.showHours(w, r)
+	case "PATCH":
+		h.updateIntake(w, r)
+	default:
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+}
+
+// showHours responds with the current opening interval of the restaurant.
+func (h *HoursService) showHours(w http.ResponseWriter, r *http.Request) {
+	hours := h.restaurant.capacity.Hours
+	oh := OpeningHours{
+		Intake:     h.restaurant.intakeOpen(),
+		AlwaysOpen: hours.AlwaysOpen(),
+	}
+	if !oh.AlwaysOpen {
+		var ok bool
+		oh.OpensAt, oh.ClosesAt, ok = hours.Interval(time.Now())
+		if !ok {
+			http.Error(w, "Restaurant does not open", http.StatusNotFound)
+			return
+		}
+	}
+	json.NewEncoder(w).Encode(&oh)
+}
+
+// updateIntake opens ("open") or closes ("close") the order intake.
+func (h *HoursService) updateIntake(w http.ResponseWriter, r *http.Request) {
+	var open bool
+	switch action := r.URL.Query().Get("action"); action {
+	case "open":
+		open = true
+	case "close":
+		open = false
+	default:
+		http.Error(w, "Unknown update action: "+action, http.StatusUnprocessableEntity)
+		return
+	}
+
+	intake := Intake{Open: open, UpdatedAt: time.Now()}
+	err := store.PutJSON(h.restaurant.store, intakeTable, intakeKey, &intake)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%+v", intake)
+}
+
+// intakeOpen returns true unless the order intake was closed.
+func (h *RestaurantService) intakeOpen() bool {
+	var intake Intake
+	err := store.GetJSON(h.store, intakeTable, intakeKey, &intake)
+	if err != nil {
+		if err != store.ErrNotFound {
+			common.Logger().Error("Failed to read order intake", zap.Error(err))
+		}
+		return true
+	}
+	return intake.Open
+}
+
+// Hours returns the opening hours of the restaurant.
+func (h *RestaurantService) Hours() *common.Hours {
+	return h.capacity.Hours
+}
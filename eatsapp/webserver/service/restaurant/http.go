@@ -1,9 +1,14 @@
 package restaurant
 
 import (
+	config "github.com/venkat1109/cadence-codelab/common"
 	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
 	"go.uber.org/cadence"
+	"io/fs"
 	"net/http"
+	"sync"
+	"time"
 )
 
 type (
@@ -11,14 +16,25 @@ type (
 	// RestaurantService implements handlers for requests sent
 	// to the restaurant http service
 	RestaurantService struct {
-		client cadence.Client
-		state  RestaurantState
+		client   cadence.Client
+		menu     *common.Menu
+		store    store.Store
+		capacity *Capacity
+		channels []*retryingChannel
+
+		stockLock sync.Mutex // serializes stock reservations
+
+		etaLock    sync.Mutex // guards the ETA republishing state below
+		etaRunning bool
+		etaPending bool
 	}
 
 	// RestaurantState models a restaurant order wheel.
 	RestaurantState struct {
-		menu   *common.Menu
 		Orders map[string]*Order
+		Rating common.Rating
+		Items  []*common.Item
+		Intake bool
 	}
 
 	// Order models a restaurant order.
@@ -31,8 +47,29 @@ type (
 		Status       OrderStatus
 		ReadySignal  *SignalParam
 		PickUpSignal *SignalParam
+		Rating       int
+		Tickets      []*Ticket
+		Changes      *OrderChanges // items rejected or substituted on acceptance
+		Receipts     []*DeliveryReceipt
+		PrepEstimate time.Duration // ETA estimated when the order reached the kitchen
+		ReadyBy      time.Time     // ready time last published to the eats workflow
+		ReadyAt      time.Time
+		CreatedAt    time.Time
+	}
+
+	// Ticket models the part of an order prepared by a single kitchen station.
+	Ticket struct {
+		OrderID   string
+		Station   string
+		Items     []*common.Item
+		Status    TicketStatus
+		CreatedAt time.Time
+		BumpedAt  time.Time
 	}
 
+	// TicketStatus is the type that represents the status of a kitchen ticket
+	TicketStatus string
+
 	// SignalParam stores the value needed to send a signal to a workflow.
 	SignalParam struct {
 		WorkflowID string
@@ -52,19 +89,38 @@ const (
 	OSSent                  = "SENT"
 )
 
+// Values representing kitchen ticket status.
+const (
+	TSOpen TicketStatus = "OPEN"
+	TSDone              = "DONE"
+)
+
 // NewService returns a new instance of the RestaurantService object.
-func NewService(c cadence.Client, menuFile string) *RestaurantService {
-	menu, err := common.NewMenu(menuFile)
+// The default menu is read from the menu file of the assets.
+func NewService(c cadence.Client, assets fs.FS, menuFile string, s store.Store, cfg config.RestaurantConfig) *RestaurantService {
+	menu, err := common.NewMenu(assets, menuFile)
 	if err != nil {
 		panic("error loading menu file")
 	}
-	return &RestaurantService{
-		client: c,
-		state: RestaurantState{
-			menu:   menu,
-			Orders: make(map[string]*Order),
-		},
+	capacity, err := NewCapacity(cfg)
+	if err != nil {
+		panic("error loading restaurant capacity: " + err.Error())
+	}
+	channels, err := newChannels(cfg.Channels)
+	if err != nil {
+		panic("error loading order channels: " + err.Error())
+	}
+	h := &RestaurantService{
+		client:   c,
+		menu:     menu,
+		store:    s,
+		capacity: capacity,
+		channels: channels,
+	}
+	if err := h.loadMenu(); err != nil {
+		panic("error loading menu from store: " + err.Error())
 	}
+	return h
 }
 
 func (h *RestaurantService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -82,5 +138,5 @@ func (h *RestaurantService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *RestaurantService) GetMenu() *common.Menu {
-	return h.state.menu
+	return h.menu
 }
@@ -0,0 +1,225 @@
+package restaurant
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+	"go.uber.org/zap"
+)
+
+type (
+	// KitchenService implements the handlers for requests
+	// sent to the restaurant kitchen display http service
+	KitchenService struct {
+		restaurant *RestaurantService
+	}
+
+	// KitchenPage models the data displayed on a kitchen station dashboard.
+	KitchenPage struct {
+		Station  string
+		Stations []string
+		Tickets  []*Ticket
+	}
+)
+
+var errTicketNotFound = errors.New("ticket not found")
+
+// NewKitchenService returns a new instance of the KitchenService object.
+func NewKitchenService(restaurant *RestaurantService) *KitchenService {
+	return &KitchenService{
+		restaurant: restaurant,
+	}
+}
+
+func (h *KitchenService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.showTickets(w, r)
+	case "POST":
+		h.addTickets(w, r)
+	case "PATCH":
+		h.updateTicket(w, r)
+	default:
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+}
+
+// showTickets renders the open tickets of the station passed as
+// the "station" param, or of all stations if none is specified.
+func (h *KitchenService) showTickets(w http.ResponseWriter, r *http.Request) {
+	state, err := h.restaurant.state()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	page := KitchenPage{
+		Station:  r.URL.Query().Get("station"),
+		Stations: common.Stations,
+	}
+	for _, order := range state.Orders {
+		// cancelled orders leave the kitchen
+		if order.Status != OSPreparing {
+			continue
+		}
+		for _, t := range order.Tickets {
+			if t.Status == TSOpen && (len(page.Station) == 0 || t.Station == page.Station) {
+				page.Tickets = append(page.Tickets, t)
+			}
+		}
+	}
+	sort.Slice(page.Tickets, func(i, j int) bool {
+		return page.Tickets[i].CreatedAt.Before(page.Tickets[j].CreatedAt)
+	})
+	common.ViewHandler(w, r, &page)
+}
+
+// addTickets splits the order passed as the "id" form value into one
+// ticket per kitchen station and responds with the list of stations.
+func (h *KitchenService) addTickets(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	orderID := r.Form.Get("id")
+	order, err := h.restaurant.modifyOrder(orderID, func(order *Order) error {
+		// tickets are only split once, so that retried requests are safe
+		if len(order.Tickets) == 0 {
+			order.Tickets = splitTickets(order, time.Now())
+		}
+		return nil
+	})
+	if err == store.ErrNotFound {
+		http.Error(w, "Order not found: "+orderID, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	stations := make([]string, 0, len(order.Tickets))
+	for _, t := range order.Tickets {
+		stations = append(stations, t.Station)
+	}
+	json.NewEncoder(w).Encode(stations)
+}
+
+// updateTicket bumps the ticket of a station once its items are prepared.
+// The order becomes ready as soon as all of its tickets are bumped and
+// the eats.OrderWorkflow is signaled, bumping the ticket again retries
+// a failed signal.
+func (h *KitchenService) updateTicket(w http.ResponseWriter, r *http.Request) {
+	orderID := r.URL.Query().Get("id")
+	station := r.URL.Query().Get("station")
+	action := r.URL.Query().Get("action")
+	if action != "bump" {
+		http.Error(w, "Unknown update action: "+action, http.StatusUnprocessableEntity)
+		return
+	}
+
+	order, err := h.restaurant.modifyOrder(orderID, func(order *Order) error {
+		ticket := order.ticket(station)
+		if ticket == nil {
+			return errTicketNotFound
+		}
+		if ticket.Status != TSDone {
+			ticket.Status = TSDone
+			ticket.BumpedAt = time.Now()
+		}
+		return nil
+	})
+	if err == store.ErrNotFound || err == errTicketNotFound {
+		http.Error(w, "Ticket not found: "+orderID+"/"+station, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if order.Status == OSPreparing && order.ticketsDone() {
+		order, err = h.restaurant.signalReady(order)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := h.restaurant.recordPrepTime(order); err != nil {
+			common.Logger().Error("Failed to record prep time", zap.String("order", order.ID), zap.Error(err))
+		}
+	}
+	fmt.Fprintf(w, "%+v", order)
+	// the bumped ticket shortens the queue of the orders behind it
+	h.restaurant.scheduleETAs()
+}
+
+// signalReady signals the eats.OrderWorkflow and then marks the order as
+// ready, the order is left as is if the signal fails.
+func (h *RestaurantService) signalReady(order *Order) (*Order, error) {
+	err := h.client.SignalWorkflow(order.ReadySignal.WorkflowID, order.ReadySignal.RunID, order.ID, "ORDER_READY")
+	if err != nil {
+		return nil, err
+	}
+	return h.modifyOrder(order.ID, func(order *Order) error {
+		if order.Status == OSPreparing {
+			order.Status = OSReady
+			order.ReadyAt = time.Now()
+		}
+		return nil
+	})
+}
+
+// splitTickets groups the items of the order by the station preparing them.
+func splitTickets(order *Order, now time.Time) []*Ticket {
+	var tickets []*Ticket
+	byStation := make(map[string]*Ticket)
+	for _, item := range order.Items {
+		station := item.KitchenStation()
+		ticket, ok := byStation[station]
+		if !ok {
+			ticket = &Ticket{
+				OrderID:   order.ID,
+				Station:   station,
+				Status:    TSOpen,
+				CreatedAt: now,
+			}
+			byStation[station] = ticket
+			tickets = append(tickets, ticket)
+		}
+		ticket.Items = append(ticket.Items, item)
+	}
+	return tickets
+}
+
+// ticket returns the ticket of the order for the specified station.
+func (o *Order) ticket(station string) *Ticket {
+	for _, t := range o.Tickets {
+		if t.Station == station {
+			return t
+		}
+	}
+	return nil
+}
+
+// ticketsDone returns true once the order was split into tickets
+// and every station bumped its ticket.
+func (o *Order) ticketsDone() bool {
+	if len(o.Tickets) == 0 {
+		return false
+	}
+	for _, t := range o.Tickets {
+		if t.Status != TSDone {
+			return false
+		}
+	}
+	return true
+}
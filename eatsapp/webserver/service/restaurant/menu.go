@@ -0,0 +1,344 @@
+package restaurant
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+)
+
+type (
+	// MenuService implements the handlers for requests
+	// sent to the restaurant menu management http service
+	MenuService struct {
+		sync.Mutex // serializes item creation
+		restaurant *RestaurantService
+	}
+
+	// MenuItemForm models the form used to edit a menu item.
+	MenuItemForm struct {
+		Item     *common.Item
+		Stations []string
+	}
+
+	// MenuAdminPage models the data displayed on the menu admin page.
+	MenuAdminPage struct {
+		Forms []*MenuItemForm
+		New   *MenuItemForm
+	}
+)
+
+const (
+	menuTable = "menu-items"
+
+	maxImageSize = 5 << 20
+)
+
+// ImagesDir is where uploaded item images are stored, it is
+// served by the static file server under the same path.
+const ImagesDir = "eatsapp/webserver/assets/images/uploads"
+
+var errVersionConflict = errors.New("the item was changed by someone else, reload and try again")
+
+// invalidItemError wraps the validation errors of a submitted item.
+type invalidItemError struct {
+	error
+}
+
+func isInvalidItem(err error) bool {
+	_, ok := err.(invalidItemError)
+	return ok
+}
+
+// NewMenuService returns a new instance of the MenuService object.
+func NewMenuService(restaurant *RestaurantService) *MenuService {
+	return &MenuService{
+		restaurant: restaurant,
+	}
+}
+
+func (h *MenuService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.showMenu(w, r)
+	case "POST":
+		h.addItem(w, r)
+	case "PATCH":
+		h.updateItem(w, r)
+	case "DELETE":
+		h.deleteItem(w, r)
+	default:
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *MenuService) showMenu(w http.ResponseWriter, r *http.Request) {
+	page := MenuAdminPage{
+		New: &MenuItemForm{
+			Item:     &common.Item{Available: true},
+			Stations: common.Stations,
+		},
+	}
+	for _, item := range h.restaurant.menu.Snapshot().Items {
+		page.Forms = append(page.Forms, &MenuItemForm{
+			Item:     item,
+			Stations: common.Stations,
+		})
+	}
+	common.ViewHandler(w, r, &page)
+}
+
+// addItem adds a new item to the menu from the submitted form.
+func (h *MenuService) addItem(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseMultipartForm(maxImageSize)
+	if err != nil && err != http.ErrNotMultipart {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	h.Lock()
+	defer h.Unlock()
+
+	item := &common.Item{
+		ID:        h.restaurant.menu.NextID(),
+		Available: true,
+		Version:   1,
+	}
+	if err := readItemForm(r, item); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err := saveImage(r, item); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = store.PutJSON(h.restaurant.store, menuTable, item.ID, item)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.restaurant.menu.Put(item)
+	json.NewEncoder(w).Encode(item)
+}
+
+// updateItem changes the item passed as the "id" param. The "version" param
+// must match the stored version of the item, so that concurrent edits are
+// detected instead of silently overwriting each other.
+func (h *MenuService) updateItem(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseMultipartForm(maxImageSize)
+	if err != nil && err != http.ErrNotMultipart {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	itemID := r.URL.Query().Get("id")
+	version, err := strconv.Atoi(r.URL.Query().Get("version"))
+	if err != nil {
+		http.Error(w, "Invalid item version: "+err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	var item common.Item
+	err = store.UpdateJSON(h.restaurant.store, menuTable, itemID, &item, func() error {
+		if item.Version != version {
+			return errVersionConflict
+		}
+		if err := readItemForm(r, &item); err != nil {
+			return invalidItemError{err}
+		}
+		if err := saveImage(r, &item); err != nil {
+			return err
+		}
+		item.Version++
+		return nil
+	})
+	if !h.writeError(w, itemID, err) {
+		return
+	}
+	h.restaurant.menu.Put(&item)
+	json.NewEncoder(w).Encode(&item)
+}
+
+// deleteItem removes the item passed as the "id" param from the menu,
+// provided the "version" param matches the stored version of the item.
+func (h *MenuService) deleteItem(w http.ResponseWriter, r *http.Request) {
+	itemID := r.URL.Query().Get("id")
+	version, err := strconv.Atoi(r.URL.Query().Get("version"))
+	if err != nil {
+		http.Error(w, "Invalid item version: "+err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	var item common.Item
+	err = store.UpdateJSON(h.restaurant.store, menuTable, itemID, &item, func() error {
+		if item.Version != version {
+			return errVersionConflict
+		}
+		return nil
+	})
+	if !h.writeError(w, itemID, err) {
+		return
+	}
+
+	err = h.restaurant.store.Delete(menuTable, itemID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.restaurant.menu.Remove(itemID)
+	fmt.Fprintf(w, "%+v", item)
+}
+
+// writeError responds with the status matching err, returning true if there was no error.
+func (h *MenuService) writeError(w http.ResponseWriter, itemID string, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case err == store.ErrNotFound:
+		http.Error(w, "Item not found: "+itemID, http.StatusNotFound)
+	case err == errVersionConflict:
+		http.Error(w, err.Error(), http.StatusConflict)
+	case isInvalidItem(err):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+	return false
+}
+
+// loadMenu replaces the menu with the items persisted in the store, seeding
+// the store from the menu file the first time the restaurant starts.
+func (h *RestaurantService) loadMenu() error {
+	values, err := h.store.List(menuTable)
+	if err != nil {
+		return err
+	}
+
+	if len(values) == 0 {
+		for _, item := range h.menu.Snapshot().Items {
+			seeded := *item
+			seeded.Version = 1
+			if err := store.PutJSON(h.store, menuTable, seeded.ID, &seeded); err != nil {
+				return err
+			}
+			h.menu.Put(&seeded)
+		}
+		return nil
+	}
+
+	items := make([]*common.Item, 0, len(values))
+	for _, v := range values {
+		var item common.Item
+		if err := json.Unmarshal(v, &item); err != nil {
+			return err
+		}
+		items = append(items, &item)
+	}
+	h.menu.Replace(items)
+	return nil
+}
+
+// readItemForm updates the item with the fields present in the form.
+func readItemForm(r *http.Request, item *common.Item) error {
+	if v, ok := r.Form["name"]; ok {
+		item.Name = strings.TrimSpace(v[0])
+	}
+	if v, ok := r.Form["description"]; ok {
+		item.Description = strings.TrimSpace(v[0])
+	}
+	if v, ok := r.Form["station"]; ok {
+		item.Station = v[0]
+	}
+	if v, ok := r.Form["price"]; ok {
+		price, err := strconv.ParseFloat(v[0], 32)
+		if err != nil || price < 0 {
+			return fmt.Errorf("invalid price: %v", v[0])
+		}
+		item.Price = float32(price)
+	}
+	if v, ok := r.Form["prep_time"]; ok && len(v[0]) > 0 {
+		prepTime, err := time.ParseDuration(v[0])
+		if err != nil || prepTime < 0 {
+			return fmt.Errorf("invalid prep time: %v", v[0])
+		}
+		item.PrepTime = prepTime
+	}
+	if v, ok := r.Form["available"]; ok {
+		item.Available = v[0] == "true" || v[0] == "on"
+	}
+	if v, ok := r.Form["track_stock"]; ok {
+		item.TrackStock = v[0] == "true" || v[0] == "on"
+	}
+	if v, ok := r.Form["stock"]; ok && len(v[0]) > 0 {
+		stock, err := strconv.Atoi(v[0])
+		if err != nil || stock < 0 {
+			return fmt.Errorf("invalid stock: %v", v[0])
+		}
+		item.Stock = stock
+	}
+
+	if len(item.Name) == 0 {
+		return errors.New("item name is required")
+	}
+	if header := imageFile(r); header != nil {
+		switch strings.ToLower(filepath.Ext(header.Filename)) {
+		case ".jpg", ".jpeg", ".png", ".gif":
+		default:
+			return fmt.Errorf("unsupported image type: %v", header.Filename)
+		}
+	}
+	return nil
+}
+
+// imageFile returns the image uploaded with the form, if any.
+func imageFile(r *http.Request) *multipart.FileHeader {
+	if r.MultipartForm == nil || len(r.MultipartForm.File["image"]) == 0 {
+		return nil
+	}
+	return r.MultipartForm.File["image"][0]
+}
+
+// saveImage stores the image uploaded as the "image" file, if any, and
+// points the item to it.
+func saveImage(r *http.Request, item *common.Item) error {
+	header := imageFile(r)
+	if header == nil {
+		return nil
+	}
+
+	src, err := header.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if err := os.MkdirAll(ImagesDir, 0755); err != nil {
+		return err
+	}
+	ext := strings.ToLower(filepath.Ext(header.Filename))
+	name := fmt.Sprintf("%s-%d%s", item.ID, time.Now().Unix(), ext)
+	dst, err := os.Create(filepath.Join(ImagesDir, name))
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		return err
+	}
+	item.Image = "/" + ImagesDir + "/" + name
+	return nil
+}
@@ -0,0 +1,32 @@
+package restaurant
+
+import (
+	"fmt"
+	"net/http"
+
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+)
+
+// rateOrder records the rating given by the customer to an order.
+func (h *RestaurantService) rateOrder(w http.ResponseWriter, r *http.Request, orderID string) {
+	rating, err := common.ParseRating(r.URL.Query().Get("rating"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	order, err := h.modifyOrder(orderID, func(order *Order) error {
+		order.Rating = rating
+		return nil
+	})
+	if err == store.ErrNotFound {
+		http.Error(w, "Order not found: "+orderID, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%+v", order)
+}
@@ -0,0 +1,421 @@
+package restaurant
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/message"
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+	restaurantwf "github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/restaurant"
+	s "go.uber.org/cadence/.gen/go/shared"
+)
+
+type (
+	// ReportService implements the handlers for requests sent to the
+	// restaurant reports http service, which generates the daily sales
+	// reports and serves them to the restaurant dashboard.
+	ReportService struct {
+		restaurant *RestaurantService
+	}
+
+	// SalesReport models the sales of the restaurant over a day.
+	SalesReport struct {
+		Date          string
+		GeneratedAt   time.Time
+		Orders        int
+		Completed     int
+		Rejected      int
+		Cancelled     int
+		Failed        int
+		Revenue       float32
+		AvgPrepTime   time.Duration
+		RejectionRate float64
+		Lines         []*SalesReportLine
+	}
+
+	// SalesReportLine models a single order on the sales report.
+	SalesReportLine struct {
+		OrderID   string
+		Status    string
+		Reason    string
+		StartedAt time.Time
+		ClosedAt  time.Time
+		Revenue   float32
+		PrepTime  time.Duration
+	}
+
+	// ReportFile models the files of the report of a day.
+	ReportFile struct {
+		Date string
+		CSV  string
+		HTML string
+	}
+
+	// ReportsPage models the data displayed on the restaurant reports page.
+	ReportsPage struct {
+		Reports   []*ReportFile
+		Yesterday string
+	}
+)
+
+// Values representing the outcome of an order on the sales report.
+const (
+	SRCompleted = "COMPLETED"
+	SRRejected  = "REJECTED"
+	SRCancelled = "CANCELLED"
+	SRFailed    = "FAILED"
+)
+
+const (
+	dateFormat     = "2006-01-02"
+	reportsDir     = "eatsapp/webserver/data/reports"
+	reportPrefix   = "sales-"
+	reportPageSize = 100
+)
+
+// rejectionReasons are the failure reasons of orders the restaurant rejected.
+var rejectionReasons = map[string]bool{
+	restaurantwf.ARClosed:               true,
+	restaurantwf.ARBusy:                 true,
+	restaurantwf.ARHourlyLimit:          true,
+	restaurantwf.ItemsUnavailableReason: true,
+}
+
+// NewReportService returns a new instance of the ReportService object.
+func NewReportService(restaurant *RestaurantService) *ReportService {
+	return &ReportService{
+		restaurant: restaurant,
+	}
+}
+
+func (h *ReportService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		if len(r.URL.Query().Get("file")) > 0 {
+			h.serveReport(w, r)
+			return
+		}
+		h.showReports(w, r)
+	case "POST":
+		h.addReport(w, r)
+	default:
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+}
+
+// showReports lists the reports found in the reports directory.
+func (h *ReportService) showReports(w http.ResponseWriter, r *http.Request) {
+	files, err := ioutil.ReadDir(reportsDir)
+	if err != nil && !os.IsNotExist(err) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	loc := h.restaurant.capacity.Hours.Location()
+	page := ReportsPage{
+		Yesterday: time.Now().In(loc).AddDate(0, 0, -1).Format(dateFormat),
+	}
+	byDate := make(map[string]*ReportFile)
+	for _, f := range files {
+		ext := filepath.Ext(f.Name())
+		date := strings.TrimSuffix(strings.TrimPrefix(f.Name(), reportPrefix), ext)
+		report, ok := byDate[date]
+		if !ok {
+			report = &ReportFile{Date: date}
+			byDate[date] = report
+			page.Reports = append(page.Reports, report)
+		}
+		switch ext {
+		case ".csv":
+			report.CSV = f.Name()
+		case ".html":
+			report.HTML = f.Name()
+		}
+	}
+	sort.Slice(page.Reports, func(i, j int) bool {
+		return page.Reports[i].Date > page.Reports[j].Date
+	})
+	common.ViewHandler(w, r, &page)
+}
+
+// serveReport serves the report file passed as the "file" param.
+func (h *ReportService) serveReport(w http.ResponseWriter, r *http.Request) {
+	name := filepath.Base(r.URL.Query().Get("file"))
+	if !strings.HasPrefix(name, reportPrefix) {
+		http.Error(w, "Report not found: "+name, http.StatusNotFound)
+		return
+	}
+	http.ServeFile(w, r, filepath.Join(reportsDir, name))
+}
+
+// addReport generates the report of the day passed as the "date" param,
+// YYYY-MM-DD in the restaurant time zone, or of the previous day.
+func (h *ReportService) addReport(w http.ResponseWriter, r *http.Request) {
+	loc := h.restaurant.capacity.Hours.Location()
+	date := r.URL.Query().Get("date")
+	if len(date) == 0 {
+		date = time.Now().In(loc).AddDate(0, 0, -1).Format(dateFormat)
+	}
+	day, err := time.ParseInLocation(dateFormat, date, loc)
+	if err != nil {
+		http.Error(w, "Invalid report date: "+date, http.StatusUnprocessableEntity)
+		return
+	}
+
+	report, err := h.restaurant.salesReport(day)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := writeReport(report); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// salesReport aggregates the eats orders started during the day from the
+// closed eats workflows and the restaurant order records.
+func (h *RestaurantService) salesReport(day time.Time) (*SalesReport, error) {
+	report := &SalesReport{
+		Date:        day.Format(dateFormat),
+		GeneratedAt: time.Now(),
+	}
+
+	executions, err := h.closedOrders(day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	var prepTotal time.Duration
+	var prepCount int
+	for _, e := range executions {
+		line := &SalesReportLine{
+			OrderID:   *e.Execution.WorkflowId,
+			StartedAt: time.Unix(0, *e.StartTime),
+		}
+		if e.CloseTime != nil {
+			line.ClosedAt = time.Unix(0, *e.CloseTime)
+		}
+
+		var order Order
+		err := store.GetJSON(h.store, ordersTable, line.OrderID, &order)
+		if err != nil && err != store.ErrNotFound {
+			return nil, err
+		}
+		hasOrder := err == nil
+
+		switch *e.CloseStatus {
+		case s.WorkflowExecutionCloseStatus_COMPLETED:
+			line.Status = SRCompleted
+		case s.WorkflowExecutionCloseStatus_FAILED:
+			line.Reason, err = h.failureReason(e.Execution)
+			if err != nil {
+				return nil, err
+			}
+			line.Status = SRFailed
+			if line.Reason == message.OrderCancelledReason {
+				line.Status = SRCancelled
+			} else if rejectionReasons[line.Reason] || (hasOrder && order.Status == OSRejected) {
+				line.Status = SRRejected
+			}
+		case s.WorkflowExecutionCloseStatus_CANCELED, s.WorkflowExecutionCloseStatus_TERMINATED:
+			line.Status = SRCancelled
+		default:
+			line.Status = SRFailed
+		}
+
+		if hasOrder {
+			if prep, ok := order.prepTime(); ok {
+				line.PrepTime = prep
+				prepTotal += prep
+				prepCount++
+			}
+		}
+		if line.Status == SRCompleted {
+			line.Revenue, err = h.chargedTotal(line.OrderID, &order)
+			if err != nil {
+				return nil, err
+			}
+		}
+		report.add(line)
+	}
+
+	if prepCount > 0 {
+		report.AvgPrepTime = prepTotal / time.Duration(prepCount) / time.Second * time.Second
+	}
+	if report.Orders > 0 {
+		report.RejectionRate = float64(report.Rejected) / float64(report.Orders)
+	}
+	sort.Slice(report.Lines, func(i, j int) bool {
+		return report.Lines[i].StartedAt.Before(report.Lines[j].StartedAt)
+	})
+	return report, nil
+}
+
+// closedOrders returns the closed eats order workflows started in the time range.
+func (h *RestaurantService) closedOrders(from time.Time, to time.Time) ([]*s.WorkflowExecutionInfo, error) {
+	workflowName := message.OrderWorkflowName
+	request := &s.ListClosedWorkflowExecutionsRequest{
+		MaximumPageSize: int32Ptr(reportPageSize),
+		StartTimeFilter: &s.StartTimeFilter{
+			EarliestTime: int64Ptr(from.UnixNano()),
+			LatestTime:   int64Ptr(to.UnixNano() - 1),
+		},
+		TypeFilter: &s.WorkflowTypeFilter{
+			Name: &workflowName,
+		},
+	}
+
+	var executions []*s.WorkflowExecutionInfo
+	for {
+		rsp, err := h.client.ListClosedWorkflow(request)
+		if err != nil {
+			return nil, err
+		}
+		executions = append(executions, rsp.Executions...)
+		if len(rsp.NextPageToken) == 0 {
+			return executions, nil
+		}
+		request.NextPageToken = rsp.NextPageToken
+	}
+}
+
+// failureReason returns the reason the workflow execution failed with.
+func (h *RestaurantService) failureReason(execution *s.WorkflowExecution) (string, error) {
+	history, err := h.client.GetWorkflowHistory(*execution.WorkflowId, *execution.RunId)
+	if err != nil {
+		return "", err
+	}
+	for i := len(history.Events) - 1; i >= 0; i-- {
+		attr := history.Events[i].WorkflowExecutionFailedEventAttributes
+		if attr != nil && attr.Reason != nil {
+			return *attr.Reason, nil
+		}
+	}
+	return "", nil
+}
+
+// add adds the order to the report totals.
+func (r *SalesReport) add(line *SalesReportLine) {
+	r.Lines = append(r.Lines, line)
+	r.Orders++
+	r.Revenue += line.Revenue
+	switch line.Status {
+	case SRCompleted:
+		r.Completed++
+	case SRRejected:
+		r.Rejected++
+	case SRCancelled:
+		r.Cancelled++
+	default:
+		r.Failed++
+	}
+}
+
+// RejectionPercent returns the rejection rate as a percentage.
+func (r *SalesReport) RejectionPercent() float64 {
+	return r.RejectionRate * 100
+}
+
+// writeReport writes the CSV and the HTML report to the reports directory.
+func writeReport(report *SalesReport) error {
+	if err := os.MkdirAll(reportsDir, 0755); err != nil {
+		return err
+	}
+	name := filepath.Join(reportsDir, reportPrefix+report.Date)
+
+	var buf bytes.Buffer
+	if err := writeCSV(&buf, report); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(name+".csv", buf.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	buf.Reset()
+	if err := common.Templates.ExecuteTemplate(&buf, "restaurant-report", report); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(name+".html", buf.Bytes(), 0644)
+}
+
+// writeCSV writes one row per order followed by the totals of the day.
+func writeCSV(buf *bytes.Buffer, report *SalesReport) error {
+	w := csv.NewWriter(buf)
+	w.Write([]string{"order_id", "status", "reason", "started_at", "closed_at", "revenue", "prep_time_seconds"})
+	for _, l := range report.Lines {
+		w.Write([]string{
+			l.OrderID,
+			l.Status,
+			l.Reason,
+			l.StartedAt.Format(time.RFC3339),
+			l.ClosedAt.Format(time.RFC3339),
+			fmt.Sprintf("%.2f", l.Revenue),
+			strconv.Itoa(int(l.PrepTime.Seconds())),
+		})
+	}
+	w.Write([]string{})
+	w.Write([]string{"orders", strconv.Itoa(report.Orders)})
+	w.Write([]string{"completed", strconv.Itoa(report.Completed)})
+	w.Write([]string{"rejected", strconv.Itoa(report.Rejected)})
+	w.Write([]string{"cancelled", strconv.Itoa(report.Cancelled)})
+	w.Write([]string{"failed", strconv.Itoa(report.Failed)})
+	w.Write([]string{"revenue", fmt.Sprintf("%.2f", report.Revenue)})
+	w.Write([]string{"avg_prep_time_seconds", strconv.Itoa(int(report.AvgPrepTime.Seconds()))})
+	w.Write([]string{"rejection_rate", fmt.Sprintf("%.4f", report.RejectionRate)})
+	w.Flush()
+	return w.Error()
+}
+
+// prepTime returns the time the kitchen took to prepare the order.
+func (o *Order) prepTime() (time.Duration, bool) {
+	if o.ReadyAt.IsZero() || len(o.Tickets) == 0 {
+		return 0, false
+	}
+	return o.ReadyAt.Sub(o.Tickets[0].CreatedAt), true
+}
+
+// chargedTotal returns the amount charged for the order, net of the loyalty
+// discount and repriced after substitutions. Orders without a price record
+// are charged the price of their items.
+func (h *RestaurantService) chargedTotal(orderID string, order *Order) (float32, error) {
+	var price common.OrderPrice
+	err := store.GetJSON(h.store, common.OrderPricesTable, orderID, &price)
+	if err == store.ErrNotFound {
+		return order.total(), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return price.Total, nil
+}
+
+// total returns the price of the items of the order.
+func (o *Order) total() float32 {
+	var total float32
+	for _, item := range o.Items {
+		total += item.Price
+	}
+	return total
+}
+
+func int32Ptr(v int32) *int32 {
+	return &v
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
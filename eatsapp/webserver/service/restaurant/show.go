@@ -6,5 +6,12 @@ import (
 )
 
 func (h *RestaurantService) showOrders(w http.ResponseWriter, r *http.Request) {
-	common.ViewHandler(w, r, &h.state)
+	state, err := h.state()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	state.Items = h.menu.Snapshot().Items
+	state.Intake = h.intakeOpen()
+	common.ViewHandler(w, r, state)
 }
@@ -0,0 +1,221 @@
+package restaurant
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+	restaurantwf "github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/restaurant"
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+)
+
+type (
+	// StockService implements the handlers for requests sent
+	// to the restaurant stock http service, used during service
+	// to take items off the menu when the kitchen runs out.
+	StockService struct {
+		restaurant *RestaurantService
+	}
+)
+
+var (
+	errOutOfStock      = errors.New("out of stock")
+	errOrderNotPending = errors.New("order is no longer pending")
+)
+
+// NewStockService returns a new instance of the StockService object.
+func NewStockService(restaurant *RestaurantService) *StockService {
+	return &StockService{
+		restaurant: restaurant,
+	}
+}
+
+func (h *StockService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "PATCH":
+		h.updateStock(w, r)
+	default:
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+}
+
+// updateStock marks the item passed as the "id" param out of stock ("out"),
+// back in stock ("in"), or sets the number of items left ("set" with "count").
+func (h *StockService) updateStock(w http.ResponseWriter, r *http.Request) {
+	itemID := r.URL.Query().Get("id")
+	action := r.URL.Query().Get("action")
+
+	var fn func(item *common.Item) error
+	switch action {
+	case "out":
+		fn = func(item *common.Item) error {
+			item.Available = false
+			return nil
+		}
+	case "in":
+		fn = func(item *common.Item) error {
+			item.Available = true
+			return nil
+		}
+	case "set":
+		count, err := strconv.Atoi(r.URL.Query().Get("count"))
+		if err != nil || count < 0 {
+			http.Error(w, "Invalid stock count: "+r.URL.Query().Get("count"), http.StatusUnprocessableEntity)
+			return
+		}
+		fn = func(item *common.Item) error {
+			item.TrackStock = true
+			item.Stock = count
+			return nil
+		}
+	default:
+		http.Error(w, "Unknown update action: "+action, http.StatusUnprocessableEntity)
+		return
+	}
+
+	item, err := h.restaurant.modifyItem(itemID, fn)
+	if err == store.ErrNotFound {
+		http.Error(w, "Item not found: "+itemID, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// orders not accepted yet can no longer be made as placed
+	if !item.Available {
+		h.restaurant.returnOrdersWith(item.ID)
+	}
+	json.NewEncoder(w).Encode(item)
+}
+
+// modifyItem atomically applies fn to the stored menu item and
+// publishes the new version of the item on the menu.
+func (h *RestaurantService) modifyItem(itemID string, fn func(item *common.Item) error) (*common.Item, error) {
+	var item common.Item
+	err := store.UpdateJSON(h.store, menuTable, itemID, &item, func() error {
+		if err := fn(&item); err != nil {
+			return err
+		}
+		item.Version++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	h.menu.Put(&item)
+	return &item, nil
+}
+
+// reserveItems takes the items of a new order out of stock, returning
+// the IDs of the items that are unavailable. Nothing is reserved
+// unless all the items are available.
+func (h *RestaurantService) reserveItems(ids []string) ([]string, error) {
+	h.stockLock.Lock()
+	defer h.stockLock.Unlock()
+
+	if unavailable := h.menu.Unavailable(ids); len(unavailable) > 0 {
+		return unavailable, nil
+	}
+
+	for i, id := range ids {
+		_, err := h.modifyItem(id, func(item *common.Item) error {
+			if !item.InStock() {
+				return errOutOfStock
+			}
+			if item.TrackStock {
+				item.Stock--
+			}
+			return nil
+		})
+		if err == errOutOfStock {
+			h.restockItems(ids[:i])
+			return []string{id}, nil
+		}
+		if err != nil {
+			h.restockItems(ids[:i])
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// restockItems puts the items of an order that will not be prepared back in stock.
+func (h *RestaurantService) restockItems(ids []string) {
+	for _, id := range ids {
+		_, err := h.modifyItem(id, func(item *common.Item) error {
+			if item.TrackStock {
+				item.Stock++
+			}
+			return nil
+		})
+		if err != nil && err != store.ErrNotFound {
+			common.Logger().Error("Failed to restock item", zap.String("item", id), zap.Error(err))
+		}
+	}
+}
+
+// returnOrdersWith sends the pending orders that contain the item back
+// to the customer, failing PlaceOrderActivity with the unavailable items
+// so that the eats.OrderWorkflow asks the customer for a substitution.
+func (h *RestaurantService) returnOrdersWith(itemID string) {
+	state, err := h.state()
+	if err != nil {
+		common.Logger().Error("Failed to list orders to return", zap.String("item", itemID), zap.Error(err))
+		return
+	}
+
+	for id, order := range state.Orders {
+		if order.Status != OSPending || !order.hasItem(itemID) {
+			continue
+		}
+
+		returned, record, err := h.readOrder(id)
+		if err != nil || returned.Status != OSPending {
+			continue
+		}
+		// the order is stored before the activity is failed, which cannot be
+		// undone, so that an order accepted meanwhile is left as is
+		prev := *returned
+		returned.Status = OSRejected
+		if err := h.saveOrder(returned, record); err != nil {
+			common.Logger().Error("Failed to return order", zap.String("order", id), zap.Error(err))
+			continue
+		}
+		unavailable := h.menu.Unavailable(returned.itemIDs())
+		reason := cadence.NewCustomError(restaurantwf.ItemsUnavailableReason, unavailable)
+		if err := h.client.CompleteActivity(returned.TaskToken, nil, reason); err != nil {
+			common.Logger().Error("Failed to return order", zap.String("order", id), zap.Error(err))
+			if err := h.rollbackOrder(returned, &prev); err != nil {
+				common.Logger().Error("Failed to roll back order", zap.String("order", id), zap.Error(err))
+			}
+			continue
+		}
+		h.restockItems(returned.itemIDs())
+	}
+}
+
+// hasItem returns true if the order contains the item.
+func (o *Order) hasItem(itemID string) bool {
+	for _, item := range o.Items {
+		if item.ID == itemID {
+			return true
+		}
+	}
+	return false
+}
+
+// itemIDs returns the IDs of the items in the order.
+func (o *Order) itemIDs() []string {
+	ids := make([]string, 0, len(o.Items))
+	for _, item := range o.Items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
@@ -0,0 +1,79 @@
+package restaurant
+
+import (
+	"encoding/json"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+)
+
+const (
+	ordersTable = "restaurant-orders"
+)
+
+// readOrder returns the stored order along with its record, for saveOrder.
+func (h *RestaurantService) readOrder(orderID string) (*Order, []byte, error) {
+	data, err := h.store.Get(ordersTable, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var order Order
+	if err := json.Unmarshal(data, &order); err != nil {
+		return nil, nil, err
+	}
+	return &order, data, nil
+}
+
+// saveOrder atomically stores the changes made to the order since its record
+// was read by readOrder, store.ErrConflict if they were changed since.
+func (h *RestaurantService) saveOrder(order *Order, record []byte) error {
+	return store.MergeJSON(h.store, ordersTable, order.ID, record, order)
+}
+
+// rollbackOrder restores the order stored by saveOrder to its previous
+// value, when the calls that complete the change failed.
+func (h *RestaurantService) rollbackOrder(order *Order, prev *Order) error {
+	record, err := json.Marshal(order)
+	if err != nil {
+		return err
+	}
+	return store.MergeJSON(h.store, ordersTable, order.ID, record, prev)
+}
+
+func (h *RestaurantService) putOrder(order *Order) error {
+	return store.PutJSON(h.store, ordersTable, order.ID, order)
+}
+
+// modifyOrder atomically applies fn to the stored order and returns the result.
+func (h *RestaurantService) modifyOrder(orderID string, fn func(order *Order) error) (*Order, error) {
+	var order Order
+	err := store.UpdateJSON(h.store, ordersTable, orderID, &order, func() error {
+		return fn(&order)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// state returns a snapshot of the stored orders.
+func (h *RestaurantService) state() (*RestaurantState, error) {
+	orders, err := h.store.List(ordersTable)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &RestaurantState{
+		Orders: make(map[string]*Order, len(orders)),
+	}
+	for k, v := range orders {
+		var order Order
+		if err := json.Unmarshal(v, &order); err != nil {
+			return nil, err
+		}
+		state.Orders[k] = &order
+		if order.Rating > 0 {
+			state.Rating.Add(order.Rating)
+		}
+	}
+	return state, nil
+}
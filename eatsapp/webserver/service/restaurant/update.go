@@ -4,29 +4,76 @@ import (
 	//"errors"
 	"fmt"
 	"net/http"
+
+	common "github.com/venkat1109/cadence-codelab/eatsapp/webserver/service"
+	"github.com/venkat1109/cadence-codelab/eatsapp/webserver/service/store"
+	"go.uber.org/zap"
 )
 
 func (h *RestaurantService) updateOrder(w http.ResponseWriter, r *http.Request) {
 
 	orderID := r.URL.Query().Get("id")
-	order, ok := h.state.Orders[orderID]
-	if !ok {
-		http.Error(w, "Order not found: "+orderID, http.StatusNotFound)
-		return
-	}
-
 	action := r.URL.Query().Get("action")
 	if len(action) == 0 {
 		http.Error(w, "No update action specified! "+action, http.StatusUnprocessableEntity)
 		return
 	}
+	switch action {
+	case "rate":
+		h.rateOrder(w, r, orderID)
+		return
+	case "modify":
+		h.modifyItems(w, r, orderID)
+		return
+	case "cancel":
+		h.cancelOrder(w, r, orderID)
+		return
+	}
 
-	h.handleAction(r, order, action)
+	// the actions complete activities and signal workflows, which is not
+	// done holding the store and cannot be undone, so the order is stored
+	// before they are made
+	order, record, err := h.readOrder(orderID)
+	if err == store.ErrNotFound {
+		http.Error(w, "Order not found: "+orderID, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	prev := *order
+	complete := h.handleAction(r, order, action)
+	err = h.saveOrder(order, record)
+	if err == store.ErrConflict {
+		http.Error(w, "Order changed concurrently: "+orderID, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if complete != nil {
+		if err := complete(); err != nil {
+			if rerr := h.rollbackOrder(order, &prev); rerr != nil {
+				common.Logger().Error("Failed to roll back order", zap.String("order", orderID), zap.Error(rerr))
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// declined orders give their items back
+	if order.Status == OSRejected && prev.Status != OSRejected {
+		h.restockItems(order.itemIDs())
+	}
 	fmt.Fprintf(w, "%+v", order)
 }
 
-func (h *RestaurantService) handleAction(r *http.Request, order *Order, action string) {
-	return
+// handleAction takes the action corresponding to the specified action type,
+// returning the calls that complete the action once the order is stored
+func (h *RestaurantService) handleAction(r *http.Request, order *Order, action string) func() error {
+	return nil
 }
 
 func getSignalParams(r *http.Request) *SignalParam {
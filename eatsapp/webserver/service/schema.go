@@ -0,0 +1,109 @@
+package service
+
+import (
+	"path"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	bytesType    = reflect.TypeOf([]byte(nil))
+)
+
+// schemaOf returns the OpenAPI schema of the sample value as encoded by
+// encoding/json. Named struct types are added to schemas and referenced.
+func schemaOf(sample interface{}, schemas map[string]interface{}) map[string]interface{} {
+	if sample == nil {
+		return map[string]interface{}{}
+	}
+	return typeSchema(reflect.TypeOf(sample), schemas)
+}
+
+func typeSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case durationType:
+		return map[string]interface{}{"type": "integer", "format": "int64", "description": "nanoseconds"}
+	case bytesType:
+		return map[string]interface{}{"type": "string", "format": "byte"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem(), schemas)}
+	case reflect.Struct:
+		if len(t.Name()) == 0 {
+			return structSchema(t, schemas)
+		}
+		name := schemaName(t)
+		if _, ok := schemas[name]; !ok {
+			// registered before recursing so that recursive types terminate
+			schemas[name] = map[string]interface{}{}
+			schemas[name] = structSchema(t, schemas)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	}
+	return map[string]interface{}{}
+}
+
+func structSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	properties := make(map[string]interface{})
+	addProperties(t, properties, schemas)
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
+
+// addProperties adds the JSON encoded fields of the struct, including
+// the fields of embedded structs, to properties.
+func addProperties(t reflect.Type, properties map[string]interface{}, schemas map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		ft := field.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if field.Anonymous && len(name) == 0 && ft.Kind() == reflect.Struct {
+			addProperties(ft, properties, schemas)
+			continue
+		}
+		if len(field.PkgPath) > 0 {
+			continue // unexported
+		}
+		if len(name) == 0 {
+			name = field.Name
+		}
+		properties[name] = typeSchema(field.Type, schemas)
+	}
+}
+
+// schemaName qualifies the type name with its package, e.g. "restaurant.Order".
+func schemaName(t reflect.Type) string {
+	if len(t.PkgPath()) == 0 {
+		return t.Name()
+	}
+	return path.Base(t.PkgPath()) + "." + t.Name()
+}
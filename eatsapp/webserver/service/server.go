@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	config "github.com/venkat1109/cadence-codelab/common"
+	"go.uber.org/zap"
+)
+
+const defaultShutdownTimeout = time.Second * 10
+
+// NewServer returns the HTTP server of the handler, listening on the
+// configured address with the configured timeouts.
+func NewServer(cfg config.WebserverConfig, h http.Handler) *http.Server {
+	addr := cfg.Addr
+	if len(addr) == 0 {
+		addr = config.DefaultWebserverAddr
+	}
+	return &http.Server{
+		Addr:         addr,
+		Handler:      h,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+	}
+}
+
+// ListenAndServe runs the server until it fails or the process receives
+// SIGINT or SIGTERM. On a signal, the server stops accepting connections
+// and waits up to the shutdown timeout for the requests in flight.
+func ListenAndServe(srv *http.Server, shutdownTimeout time.Duration, logger *zap.Logger) error {
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = defaultShutdownTimeout
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		logger.Info("Starting HTTP server", zap.String("addr", srv.Addr))
+		errc <- srv.ListenAndServe()
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	select {
+	case err := <-errc:
+		return err
+	case sig := <-signals:
+		logger.Info("Shutting down HTTP server", zap.String("signal", sig.String()))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		return err
+	}
+	if err := <-errc; err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
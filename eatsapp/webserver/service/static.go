@@ -0,0 +1,126 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// StaticHandler serves the stylesheets, scripts and images of the
+	// assets, and the images uploaded to the uploads directory. Other
+	// assets, e.g. the templates, and directory listings are not served.
+	StaticHandler struct {
+		assets  fs.FS
+		uploads string
+		maxAge  time.Duration
+		etags   sync.Map // asset path to ETag
+	}
+)
+
+// AssetsPrefix is the URL path the assets are served under.
+const AssetsPrefix = "/eatsapp/webserver/assets/"
+
+// staticDirs lists the asset directories served to the browsers.
+var staticDirs = []string{"css/", "js/", "images/"}
+
+// uploadsPrefix is the path of the uploaded images in the assets URLs.
+const uploadsPrefix = "images/uploads/"
+
+// NewStaticHandler returns the handler serving the assets, which browsers
+// may cache for maxAge, and the images uploaded to the uploads directory.
+// Zero maxAge makes browsers revalidate the assets on every use.
+func NewStaticHandler(assets fs.FS, uploads string, maxAge time.Duration) *StaticHandler {
+	return &StaticHandler{
+		assets:  assets,
+		uploads: uploads,
+		maxAge:  maxAge,
+	}
+}
+
+func (h *StaticHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		http.Error(w, "", http.StatusMethodNotAllowed)
+		return
+	}
+	name := path.Clean(strings.TrimPrefix(r.URL.Path, AssetsPrefix))
+	if !isStatic(name) {
+		http.NotFound(w, r)
+		return
+	}
+
+	var file fs.File
+	var err error
+	if strings.HasPrefix(name, uploadsPrefix) {
+		if len(h.uploads) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+		file, err = os.Open(path.Join(h.uploads, strings.TrimPrefix(name, uploadsPrefix)))
+	} else {
+		file, err = h.assets.Open(name)
+	}
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+
+	if h.maxAge > 0 {
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(h.maxAge/time.Second)))
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	// embedded files have no modification time to revalidate with
+	if info.ModTime().IsZero() {
+		if etag, err := h.etag(name, content); err == nil {
+			w.Header().Set("ETag", etag)
+		}
+	}
+	http.ServeContent(w, r, name, info.ModTime(), content)
+}
+
+// etag returns the ETag of the asset, hashing its content the first time.
+func (h *StaticHandler) etag(name string, content io.ReadSeeker) (string, error) {
+	if etag, ok := h.etags.Load(name); ok {
+		return etag.(string), nil
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	etag := `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+	h.etags.Store(name, etag)
+	return etag, nil
+}
+
+func isStatic(name string) bool {
+	for _, dir := range staticDirs {
+		if strings.HasPrefix(name, dir) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package store
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type (
+	// FileStore implements a Store that persists every table as a JSON
+	// file in a directory, so that its records survive restarts. Reads
+	// are served from memory, writes rewrite the file of the table.
+	FileStore struct {
+		*MemoryStore
+		dir string
+	}
+)
+
+const tableFileExt = ".json"
+
+// NewFileStore returns a FileStore backed by the specified
+// directory, loading the tables previously written to it.
+func NewFileStore(dir string) (*FileStore, error) {
+	s := &FileStore{
+		MemoryStore: NewMemoryStore(),
+		dir:         dir,
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*"+tableFileExt))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var records map[string]json.RawMessage
+		if err := json.Unmarshal(data, &records); err != nil {
+			return nil, err
+		}
+		table := strings.TrimSuffix(filepath.Base(file), tableFileExt)
+		for k, v := range records {
+			s.MemoryStore.put(table, k, v)
+		}
+	}
+	return s, nil
+}
+
+// Put stores the record under key in table.
+func (s *FileStore) Put(table string, key string, data []byte) error {
+	s.Lock()
+	defer s.Unlock()
+
+	records := s.copyTable(table)
+	records[key] = data
+	return s.commit(table, records)
+}
+
+// Update atomically replaces the record stored under key in table.
+func (s *FileStore) Update(table string, key string, fn func(data []byte) ([]byte, error)) error {
+	s.Lock()
+	defer s.Unlock()
+
+	data, ok := s.tables[table][key]
+	if !ok {
+		return ErrNotFound
+	}
+	data, err := fn(data)
+	if err != nil {
+		return err
+	}
+	records := s.copyTable(table)
+	records[key] = data
+	return s.commit(table, records)
+}
+
+// Delete removes the record stored under key in table.
+func (s *FileStore) Delete(table string, key string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	records := s.copyTable(table)
+	delete(records, key)
+	return s.commit(table, records)
+}
+
+// copyTable returns a copy of the records of the table, for commit.
+// Must be called with the lock held.
+func (s *FileStore) copyTable(table string) map[string][]byte {
+	records := make(map[string][]byte, len(s.tables[table])+1)
+	for k, v := range s.tables[table] {
+		records[k] = v
+	}
+	return records
+}
+
+// commit writes the records to the file of the table, and then replaces
+// the table in memory with them, so that the records in memory are never
+// ahead of the disk if the write fails. Must be called with the lock held.
+func (s *FileStore) commit(table string, records map[string][]byte) error {
+	if err := s.flush(table, records); err != nil {
+		return err
+	}
+	s.tables[table] = records
+	return nil
+}
+
+// flush writes the records to a temporary file and renames it over the
+// table file, so that a crash never leaves a partially written table. The
+// file is synced before the rename and the directory after it, otherwise
+// the rename may reach the disk before the data, or not at all.
+func (s *FileStore) flush(table string, records map[string][]byte) error {
+	raw := make(map[string]json.RawMessage, len(records))
+	for k, v := range records {
+		raw[k] = v
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+
+	file := filepath.Join(s.dir, table+tableFileExt)
+	tmp := file + ".tmp"
+	if err := writeFileSync(tmp, data); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return syncDir(s.dir)
+}
+
+// writeFileSync writes the data to the file and syncs it to the disk.
+func writeFileSync(file string, data []byte) error {
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// syncDir syncs the directory, which makes the files renamed in it durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// GetJSON decodes the record stored under key in table into value.
+func GetJSON(s Store, table string, key string, value interface{}) error {
+	data, err := s.Get(table, key)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
+
+// PutJSON stores value, encoded as JSON, under key in table.
+func PutJSON(s Store, table string, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return s.Put(table, key, data)
+}
+
+// UpdateJSON atomically decodes the record stored under key in table into
+// value, calls fn to modify value, and stores the result. The record is
+// left unchanged if fn returns an error.
+func UpdateJSON(s Store, table string, key string, value interface{}, fn func() error) error {
+	return s.Update(table, key, func(data []byte) ([]byte, error) {
+		if err := json.Unmarshal(data, value); err != nil {
+			return nil, err
+		}
+		if err := fn(); err != nil {
+			return nil, err
+		}
+		return json.Marshal(value)
+	})
+}
+
+// MergeJSON atomically stores the fields of value, a JSON object, that
+// differ from the record old it was decoded from, leaving the other fields
+// of the stored record as they are. It returns ErrConflict if another
+// writer changed one of these fields since old was read. This lets callers
+// change a record based on slow calls, e.g. to cadence, without holding
+// the store across the call.
+func MergeJSON(s Store, table string, key string, old []byte, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var before, after map[string]json.RawMessage
+	if err := json.Unmarshal(old, &before); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &after); err != nil {
+		return err
+	}
+
+	return s.Update(table, key, func(current []byte) ([]byte, error) {
+		var record map[string]json.RawMessage
+		if err := json.Unmarshal(current, &record); err != nil {
+			return nil, err
+		}
+		for field, v := range after {
+			if bytes.Equal(v, before[field]) {
+				continue
+			}
+			if !bytes.Equal(record[field], before[field]) {
+				return nil, ErrConflict
+			}
+			record[field] = v
+		}
+		for field := range before {
+			if _, ok := after[field]; !ok {
+				delete(record, field)
+			}
+		}
+		return json.Marshal(record)
+	})
+}
@@ -0,0 +1,130 @@
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+type testRecord struct {
+	Status string
+	Count  int
+	Note   string `json:",omitempty"`
+}
+
+func TestMergeJSON(t *testing.T) {
+	old := testRecord{Status: "NEW", Count: 1, Note: "ring twice"}
+	tests := []struct {
+		name    string
+		current testRecord // record stored by another writer since old was read
+		value   testRecord
+		want    testRecord
+		err     error
+	}{
+		{
+			name:    "unchanged record",
+			current: testRecord{Status: "NEW", Count: 1, Note: "ring twice"},
+			value:   testRecord{Status: "DONE", Count: 1, Note: "ring twice"},
+			want:    testRecord{Status: "DONE", Count: 1, Note: "ring twice"},
+		},
+		{
+			name:    "other field changed",
+			current: testRecord{Status: "NEW", Count: 2, Note: "ring twice"},
+			value:   testRecord{Status: "DONE", Count: 1, Note: "ring twice"},
+			want:    testRecord{Status: "DONE", Count: 2, Note: "ring twice"},
+		},
+		{
+			name:    "same field changed",
+			current: testRecord{Status: "CANCELLED", Count: 1, Note: "ring twice"},
+			value:   testRecord{Status: "DONE", Count: 1, Note: "ring twice"},
+			want:    testRecord{Status: "CANCELLED", Count: 1, Note: "ring twice"},
+			err:     ErrConflict,
+		},
+		{
+			// concurrent requests taking the same action conflict, so
+			// that only one of them goes on to call cadence
+			name:    "same change",
+			current: testRecord{Status: "DONE", Count: 1, Note: "ring twice"},
+			value:   testRecord{Status: "DONE", Count: 1, Note: "ring twice"},
+			want:    testRecord{Status: "DONE", Count: 1, Note: "ring twice"},
+			err:     ErrConflict,
+		},
+		{
+			name:    "field removed",
+			current: testRecord{Status: "NEW", Count: 2, Note: "ring twice"},
+			value:   testRecord{Status: "NEW", Count: 1},
+			want:    testRecord{Status: "NEW", Count: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewMemoryStore()
+			if err := PutJSON(s, "records", "r1", &old); err != nil {
+				t.Fatal(err)
+			}
+			record, err := s.Get("records", "r1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := PutJSON(s, "records", "r1", &tt.current); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := MergeJSON(s, "records", "r1", record, &tt.value); err != tt.err {
+				t.Fatalf("MergeJSON() = %v, want %v", err, tt.err)
+			}
+			var got testRecord
+			if err := GetJSON(s, "records", "r1", &got); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("record = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileStoreReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := PutJSON(s, "records", "r1", &testRecord{Status: "NEW"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := PutJSON(s, "records", "r2", &testRecord{Status: "NEW"}); err != nil {
+		t.Fatal(err)
+	}
+	err = UpdateJSON(s, "records", "r1", &testRecord{}, func() error { return ErrConflict })
+	if err != ErrConflict {
+		t.Fatalf("UpdateJSON() = %v, want %v", err, ErrConflict)
+	}
+	if err := s.Delete("records", "r2"); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := s.List("records")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("got %d records after reload, want 1", len(records))
+	}
+	var got testRecord
+	if err := GetJSON(s, "records", "r1", &got); err != nil || got.Status != "NEW" {
+		t.Errorf("r1 = %+v, %v after reload, want NEW", got, err)
+	}
+	if tmp, _ := ioutil.ReadDir(dir); len(tmp) != 1 {
+		t.Errorf("got %d files in the store directory, want the table file only", len(tmp))
+	}
+}
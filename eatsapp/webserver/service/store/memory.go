@@ -0,0 +1,89 @@
+package store
+
+import (
+	"sync"
+)
+
+type (
+	// MemoryStore implements a Store that keeps all records in memory.
+	MemoryStore struct {
+		sync.RWMutex
+		tables map[string]map[string][]byte
+	}
+)
+
+// NewMemoryStore returns a new, empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		tables: make(map[string]map[string][]byte),
+	}
+}
+
+// Get returns the record stored under key in table.
+func (s *MemoryStore) Get(table string, key string) ([]byte, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	data, ok := s.tables[table][key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return data, nil
+}
+
+// Put stores the record under key in table.
+func (s *MemoryStore) Put(table string, key string, data []byte) error {
+	s.Lock()
+	defer s.Unlock()
+
+	s.put(table, key, data)
+	return nil
+}
+
+// Update atomically replaces the record stored under key in table.
+func (s *MemoryStore) Update(table string, key string, fn func(data []byte) ([]byte, error)) error {
+	s.Lock()
+	defer s.Unlock()
+
+	data, ok := s.tables[table][key]
+	if !ok {
+		return ErrNotFound
+	}
+	data, err := fn(data)
+	if err != nil {
+		return err
+	}
+	s.put(table, key, data)
+	return nil
+}
+
+// Delete removes the record stored under key in table.
+func (s *MemoryStore) Delete(table string, key string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	delete(s.tables[table], key)
+	return nil
+}
+
+// List returns all the records in table.
+func (s *MemoryStore) List(table string) (map[string][]byte, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	records := make(map[string][]byte, len(s.tables[table]))
+	for k, v := range s.tables[table] {
+		records[k] = v
+	}
+	return records, nil
+}
+
+// put stores the record, must be called with the lock held.
+func (s *MemoryStore) put(table string, key string, data []byte) {
+	t, ok := s.tables[table]
+	if !ok {
+		t = make(map[string][]byte)
+		s.tables[table] = t
+	}
+	t[key] = data
+}
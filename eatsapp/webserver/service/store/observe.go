@@ -0,0 +1,67 @@
+package store
+
+type (
+	// Change describes a record that was created, updated or deleted.
+	Change struct {
+		Type  ChangeType
+		Table string
+		Key   string
+	}
+
+	// ChangeType is the type that represents the kind of a record change
+	ChangeType string
+
+	// ObservedStore implements a Store that reports every successful
+	// change made to the wrapped store to an observer.
+	ObservedStore struct {
+		Store
+		observer func(change Change)
+	}
+)
+
+// Values representing record changes.
+const (
+	ChangeCreated ChangeType = "created"
+	ChangeUpdated            = "updated"
+	ChangeDeleted            = "deleted"
+)
+
+// Observe returns a store that calls observer after every change made
+// to s. The observer is called outside of the store locks.
+func Observe(s Store, observer func(change Change)) *ObservedStore {
+	return &ObservedStore{
+		Store:    s,
+		observer: observer,
+	}
+}
+
+// Put stores the record under key in table.
+func (s *ObservedStore) Put(table string, key string, data []byte) error {
+	var changeType ChangeType = ChangeUpdated
+	if _, err := s.Store.Get(table, key); err == ErrNotFound {
+		changeType = ChangeCreated
+	}
+	if err := s.Store.Put(table, key, data); err != nil {
+		return err
+	}
+	s.observer(Change{Type: changeType, Table: table, Key: key})
+	return nil
+}
+
+// Update atomically replaces the record stored under key in table.
+func (s *ObservedStore) Update(table string, key string, fn func(data []byte) ([]byte, error)) error {
+	if err := s.Store.Update(table, key, fn); err != nil {
+		return err
+	}
+	s.observer(Change{Type: ChangeUpdated, Table: table, Key: key})
+	return nil
+}
+
+// Delete removes the record stored under key in table.
+func (s *ObservedStore) Delete(table string, key string) error {
+	if err := s.Store.Delete(table, key); err != nil {
+		return err
+	}
+	s.observer(Change{Type: ChangeDeleted, Table: table, Key: key})
+	return nil
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/venkat1109/cadence-codelab/common"
+)
+
+type (
+	// Store is a collection of tables holding records keyed by ID.
+	// Records are JSON documents encoded by the caller. Implementations
+	// are safe for concurrent use.
+	Store interface {
+		// Get returns the record stored under key in table.
+		Get(table string, key string) ([]byte, error)
+		// Put stores the record under key in table.
+		Put(table string, key string, data []byte) error
+		// Update atomically replaces the record stored under key in table
+		// with the result of fn, fn is called with the current record.
+		Update(table string, key string, fn func(data []byte) ([]byte, error)) error
+		// Delete removes the record stored under key in table.
+		Delete(table string, key string) error
+		// List returns all the records in table.
+		List(table string) (map[string][]byte, error)
+	}
+)
+
+// Values representing the supported store types.
+const (
+	TypeMemory = "memory"
+	TypeFile   = "file"
+)
+
+// ErrNotFound is returned when a record does not exist.
+var ErrNotFound = errors.New("record not found")
+
+// ErrConflict is returned by MergeJSON when a field it changes was changed
+// by another writer since the record was read.
+var ErrConflict = errors.New("record changed concurrently")
+
+// New returns the store selected by the configuration.
+func New(config common.StoreConfig) (Store, error) {
+	switch config.Type {
+	case "", TypeMemory:
+		return NewMemoryStore(), nil
+	case TypeFile:
+		return NewFileStore(config.Path)
+	default:
+		return nil, fmt.Errorf("unknown store type: %v", config.Type)
+	}
+}
+
+// healthTable holds the record written by Ping.
+const healthTable = "health"
+
+// Ping writes, reads back and removes a record, returning an error
+// if the store cannot be written, e.g. the file store disk is full.
+func Ping(s Store) error {
+	if err := s.Put(healthTable, "ping", []byte("{}")); err != nil {
+		return err
+	}
+	if _, err := s.Get(healthTable, "ping"); err != nil {
+		return err
+	}
+	return s.Delete(healthTable, "ping")
+}
+
+// SortedKeys returns the keys of the records in ascending order.
+func SortedKeys(records map[string][]byte) []string {
+	keys := make([]string, 0, len(records))
+	for k := range records {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+type (
+	// ErrorPage models the page rendered for the requests that fail.
+	ErrorPage struct {
+		Status    int
+		Title     string
+		Message   string
+		RequestID string
+	}
+)
+
+// errorTemplate is the template of the error pages.
+const errorTemplate = "error"
+
+// views lists the pages every route renders. The first page of a route
+// is rendered unless the request asks for another page of the route with
+// the "page" param, other templates, e.g. the partials, are never rendered
+// on their own.
+var views = map[string][]string{
+	"/bistro":             {"bistro"},
+	"/login":              {"login"},
+	"/eats-menu":          {"eats-menu"},
+	"/eats-orders":        {"eats-orders", "eats-order-status"},
+	"/eats-customer":      {"eats-customer"},
+	"/eats-history":       {"eats-history"},
+	"/restaurant":         {"restaurant"},
+	"/restaurant-kitchen": {"restaurant-kitchen"},
+	"/restaurant-menu":    {"restaurant-menu"},
+	"/restaurant-reports": {"restaurant-reports"},
+	"/courier":            {"courier"},
+	"/courier-earnings":   {"courier-earnings"},
+}
+
+// logger logs the errors of the services, see SetLogger.
+var logger = zap.NewNop()
+
+// SetLogger sets the logger of the errors of the services. The internal
+// errors of the requests are logged with the request ID shown on the
+// error page.
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
+// Logger returns the logger of the errors of the services, for the
+// errors that happen outside of a request or do not fail it.
+func Logger() *zap.Logger {
+	return logger
+}
+
+// viewOf returns the page the request renders, false if the route
+// does not render the page the request asks for.
+func viewOf(r *http.Request) (string, bool) {
+	pages, ok := views[r.URL.Path]
+	if !ok {
+		return "", false
+	}
+	page := r.URL.Query().Get("page")
+	if len(page) == 0 {
+		return pages[0], true
+	}
+	for _, p := range pages {
+		if p == page {
+			return page, true
+		}
+	}
+	return "", false
+}
+
+// RenderView renders the page with the status in the locale the request
+// prefers. The page is rendered in full before the response is written,
+// so that a failing template results in an error page rather than in the
+// part of the page rendered before the failure.
+func RenderView(w http.ResponseWriter, r *http.Request, status int, page string, data interface{}) error {
+	templates, locale := templatesFor(r)
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, page, data); err != nil {
+		err = fmt.Errorf("rendering %s: %v", page, err)
+		InternalError(w, r, err)
+		return err
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Language", locale)
+	w.Header().Add("Vary", "Accept-Language")
+	w.WriteHeader(status)
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// NotFound responds with the not found page.
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	renderError(w, r, &ErrorPage{
+		Status:  http.StatusNotFound,
+		Title:   "Page not found",
+		Message: "The page you are looking for does not exist.",
+	})
+}
+
+// InternalError logs the error and responds with the internal server error
+// page, which only shows the ID of the request to find the error in the logs.
+func InternalError(w http.ResponseWriter, r *http.Request, err error) {
+	logger.Error("Internal server error",
+		zap.String("request_id", RequestIDOf(r)),
+		zap.String("method", r.Method),
+		zap.String("path", r.URL.Path),
+		zap.Error(err))
+	renderError(w, r, internalErrorPage())
+}
+
+// internalErrorPage returns the page of the internal server errors.
+func internalErrorPage() *ErrorPage {
+	return &ErrorPage{
+		Status:  http.StatusInternalServerError,
+		Title:   "Something went wrong",
+		Message: "We could not complete your request, please try again later.",
+	}
+}
+
+// renderError renders the error page, as a JSON error for the clients
+// asking for JSON, and as plain text if the error page fails to render.
+func renderError(w http.ResponseWriter, r *http.Request, page *ErrorPage) {
+	page.RequestID = RequestIDOf(r)
+	if strings.Contains(r.Header.Get("Accept"), jsonContentType) {
+		WriteError(w, NewAPIError(page.Status, page.Title))
+		return
+	}
+
+	templates, locale := templatesFor(r)
+	var buf bytes.Buffer
+	if templates == nil || templates.ExecuteTemplate(&buf, errorTemplate, page) != nil {
+		http.Error(w, http.StatusText(page.Status), page.Status)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Language", locale)
+	w.Header().Add("Vary", "Accept-Language")
+	w.WriteHeader(page.Status)
+	buf.WriteTo(w)
+}
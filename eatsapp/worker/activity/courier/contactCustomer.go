@@ -0,0 +1,52 @@
+package courier
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/webserver"
+)
+
+func init() {
+	cadence.RegisterActivity(ContactCustomerActivity)
+}
+
+// ContactCustomerActivity implements the contact customer activity, which asks
+// the customer to respond after the courier failed to deliver the order.
+func ContactCustomerActivity(ctx context.Context, execution cadence.WorkflowExecution, orderID string, reason string) error {
+	logger := cadence.GetActivityLogger(ctx)
+
+	err := contactCustomer(execution, orderID, reason)
+	if err != nil {
+		logger.Info("Failed to contact customer.", zap.Error(err))
+		return err
+	}
+
+	logger.Info("Contacted customer about failed delivery.", zap.String("Order ID", orderID), zap.String("Reason", reason))
+	return nil
+}
+
+func contactCustomer(execution cadence.WorkflowExecution, orderID string, reason string) error {
+	formData := url.Values{}
+	formData.Add("id", orderID)
+	formData.Add("reason", reason)
+	formData.Add("workflow_id", execution.ID)
+	formData.Add("run_id", execution.RunID)
+
+	url := webserver.URL + "/eats-delivery"
+	rsp, err := webserver.Client.PostForm(url, formData)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return errors.New("failed to contact customer: " + rsp.Status)
+	}
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 
 	"go.uber.org/cadence"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/webserver"
 )
 
 func init() {
@@ -17,6 +19,6 @@ func DeliverOrderActivity(ctx context.Context, orderID string) (string, error) {
 }
 
 func deliver(orderID string, taskToken string) error {
-	url := "http://localhost:8090/courier?action=c_token&id=" + orderID + "&task_token=" + taskToken
+	url := webserver.URL + "/courier?action=c_token&id=" + orderID + "&task_token=" + taskToken
 	return sendPatch(url)
 }
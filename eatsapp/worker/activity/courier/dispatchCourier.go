@@ -2,11 +2,15 @@ package courier
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/url"
+	"sort"
 
 	"go.uber.org/cadence"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/webserver"
 )
 
 func init() {
@@ -19,15 +23,80 @@ func DispatchCourierActivity(ctx context.Context, orderID string) (string, error
 }
 
 func dispatch(orderID string, taskToken string) error {
+	courierID, err := selectCourier()
+	if err != nil {
+		return err
+	}
+
 	formData := url.Values{}
 	formData.Add("id", orderID)
 	formData.Add("task_token", taskToken)
+	formData.Add("courier", courierID)
 
-	url := "http://localhost:8090/courier"
-	_, err := http.PostForm(url, formData)
+	url := webserver.URL + "/courier"
+	rsp, err := webserver.Client.PostForm(url, formData)
 	if err != nil {
 		return err
 	}
+	defer rsp.Body.Close()
 
+	// the courier service refuses the job when no courier is available
+	if rsp.StatusCode != http.StatusOK {
+		return errors.New("failed to dispatch courier: " + rsp.Status)
+	}
 	return nil
 }
+
+// courierRanking is the subset of the courier shift used to select couriers.
+type courierRanking struct {
+	CourierID  string
+	Status     string
+	Capacity   int
+	ActiveJobs int
+	Rating     float32
+	Ratings    int
+}
+
+// unratedCourierRating is the rating assumed for couriers that have not been
+// rated yet, so that new couriers are neither favored nor starved of jobs.
+const unratedCourierRating = 3
+
+// selectCourier returns the available courier with the highest average
+// rating, breaking ties in favor of the courier with the fewest active jobs.
+func selectCourier() (string, error) {
+	rsp, err := webserver.Client.Get(webserver.URL + "/courier-shift")
+	if err != nil {
+		return "", err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return "", errors.New("failed to list couriers: " + rsp.Status)
+	}
+
+	var couriers []courierRanking
+	if err := json.NewDecoder(rsp.Body).Decode(&couriers); err != nil {
+		return "", err
+	}
+
+	var candidates []courierRanking
+	for _, c := range couriers {
+		if c.Status == "AVAILABLE" && c.ActiveJobs < c.Capacity {
+			if c.Ratings == 0 {
+				c.Rating = unratedCourierRating
+			}
+			candidates = append(candidates, c)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", errors.New("no courier available")
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Rating != candidates[j].Rating {
+			return candidates[i].Rating > candidates[j].Rating
+		}
+		return candidates[i].ActiveJobs < candidates[j].ActiveJobs
+	})
+	return candidates[0].CourierID, nil
+}
@@ -2,6 +2,8 @@ package courier
 
 import (
 	"net/http"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/webserver"
 )
 
 func sendPatch(url string) error {
@@ -9,8 +11,7 @@ func sendPatch(url string) error {
 	if err != nil {
 		return err
 	}
-	client := &http.Client{}
-	_, err = client.Do(req)
+	_, err = webserver.Client.Do(req)
 	if err != nil {
 		return err
 	}
@@ -5,6 +5,8 @@ import (
 	"errors"
 
 	"go.uber.org/cadence"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/webserver"
 )
 
 func init() {
@@ -17,12 +19,12 @@ func PickUpOrderActivity(ctx context.Context, execution cadence.WorkflowExecutio
 }
 
 func notifyRestaurant(execution cadence.WorkflowExecution, orderID string) error {
-	url := "http://localhost:8090/restaurant?action=p_sig&id=" + orderID +
+	url := webserver.URL + "/restaurant?action=p_sig&id=" + orderID +
 		"&workflow_id=" + execution.ID + "&run_id=" + execution.RunID
 	return sendPatch(url)
 }
 
 func pickup(orderID string, taskToken string) error {
-	url := "http://localhost:8090/courier?action=p_token&id=" + orderID + "&task_token=" + taskToken
+	url := webserver.URL + "/courier?action=p_token&id=" + orderID + "&task_token=" + taskToken
 	return sendPatch(url)
 }
@@ -0,0 +1,53 @@
+package courier
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/webserver"
+)
+
+func init() {
+	cadence.RegisterActivity(PostEarningsActivity)
+}
+
+// PostEarningsActivity implements the post courier earnings activity,
+// for the delivery of the order or, if leg is not empty, for a leg of
+// the delivery paid separately.
+func PostEarningsActivity(ctx context.Context, orderID string, leg string) error {
+	logger := cadence.GetActivityLogger(ctx)
+
+	err := postEarnings(orderID, leg)
+	if err != nil {
+		logger.Info("Failed to post courier earnings.", zap.Error(err))
+		return err
+	}
+
+	logger.Info("Successfully posted courier earnings.", zap.String("Order ID", orderID), zap.String("Leg", leg))
+	return nil
+}
+
+func postEarnings(orderID string, leg string) error {
+	formData := url.Values{}
+	formData.Add("id", orderID)
+	if len(leg) > 0 {
+		formData.Add("leg", leg)
+	}
+
+	url := webserver.URL + "/courier-earnings"
+	rsp, err := webserver.Client.PostForm(url, formData)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return errors.New("failed to post earnings: " + rsp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package courier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/webserver"
+)
+
+// ShiftReport models the availability of a courier during a shift,
+// and the shift summary once the courier went offline. Jobs lists
+// the orders the courier is currently delivering.
+type ShiftReport struct {
+	CourierID     string
+	Status        string
+	Capacity      int
+	ActiveJobs    int
+	Jobs          []string
+	StartedAt     time.Time
+	EndedAt       time.Time
+	JobsCompleted int
+	Breaks        int
+	BreakTime     time.Duration
+}
+
+func init() {
+	cadence.RegisterActivity(ReportShiftActivity)
+}
+
+// ReportShiftActivity implements the report courier shift activity.
+func ReportShiftActivity(ctx context.Context, report ShiftReport) error {
+	err := sendShiftReport(report)
+	if err != nil {
+		cadence.GetActivityLogger(ctx).Info("Failed to report courier shift.", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func sendShiftReport(report ShiftReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	url := webserver.URL + "/courier-shift?courier=" + report.CourierID
+	rsp, err := webserver.Client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return errors.New("failed to report shift: " + rsp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package courier
+
+import (
+	"context"
+
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/webserver"
+)
+
+func init() {
+	cadence.RegisterActivity(ReturnOrderActivity)
+}
+
+// ReturnOrderActivity implements the return order to restaurant activity.
+func ReturnOrderActivity(ctx context.Context, orderID string) (string, error) {
+	logger := cadence.GetActivityLogger(ctx)
+	activityInfo := cadence.GetActivityInfo(ctx)
+
+	// register token with courier service
+	err := returnOrder(orderID, string(activityInfo.TaskToken))
+	if err != nil {
+		logger.Info("Failed to send return order to courier.", zap.Error(err))
+		return "", err
+	}
+
+	return "", cadence.ErrActivityResultPending
+}
+
+func returnOrder(orderID string, taskToken string) error {
+	url := webserver.URL + "/courier?action=r_token&id=" + orderID + "&task_token=" + taskToken
+	return sendPatch(url)
+}
@@ -0,0 +1,54 @@
+package courier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/message"
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/webserver"
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+)
+
+func init() {
+	cadence.RegisterActivity(SettleEarningsActivity)
+}
+
+// SettleEarningsActivity implements the settle courier earnings activity.
+func SettleEarningsActivity(ctx context.Context) ([]message.Payout, error) {
+	logger := cadence.GetActivityLogger(ctx)
+
+	payouts, err := settle()
+	if err != nil {
+		logger.Info("Failed to settle courier earnings.", zap.Error(err))
+		return nil, err
+	}
+
+	for _, p := range payouts {
+		logger.Info("Settled courier earnings.", zap.String("Courier ID", p.CourierID),
+			zap.Float64("Amount", float64(p.Amount)), zap.Int("Entries", len(p.EntryIDs)))
+	}
+	return payouts, nil
+}
+
+func settle() ([]message.Payout, error) {
+	req, err := http.NewRequest("PATCH", webserver.URL+"/courier-earnings?action=settle", nil)
+	if err != nil {
+		return nil, err
+	}
+	rsp, err := webserver.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return nil, errors.New("failed to settle earnings: " + rsp.Status)
+	}
+
+	var payouts []message.Payout
+	err = json.NewDecoder(rsp.Body).Decode(&payouts)
+	return payouts, err
+}
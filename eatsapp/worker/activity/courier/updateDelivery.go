@@ -0,0 +1,26 @@
+package courier
+
+import (
+	"context"
+
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/webserver"
+)
+
+func init() {
+	cadence.RegisterActivity(UpdateDeliveryActivity)
+}
+
+// UpdateDeliveryActivity notifies the courier service about the
+// next step taken for an order whose delivery failed.
+func UpdateDeliveryActivity(ctx context.Context, orderID string, action string) error {
+	url := webserver.URL + "/courier?action=" + action + "&id=" + orderID
+	err := sendPatch(url)
+	if err != nil {
+		cadence.GetActivityLogger(ctx).Info("Failed to update delivery.", zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package eats
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/webserver"
+)
+
+func init() {
+	cadence.RegisterActivity(CancelRestaurantOrderActivity)
+}
+
+// CancelRestaurantOrderActivity implements the cancel restaurant order
+// activity, which takes an order the customer cancelled off the kitchen.
+func CancelRestaurantOrderActivity(ctx context.Context, orderID string) error {
+	err := cancelRestaurantOrder(orderID)
+	if err != nil {
+		cadence.GetActivityLogger(ctx).Info("Failed to cancel restaurant order.", zap.Error(err))
+		return err
+	}
+
+	cadence.GetActivityLogger(ctx).Info("Cancelled restaurant order.", zap.String("Order ID", orderID))
+	return nil
+}
+
+func cancelRestaurantOrder(orderID string) error {
+	url := webserver.URL + "/restaurant?action=cancel&id=" + orderID
+	req, err := http.NewRequest("PATCH", url, nil)
+	if err != nil {
+		return err
+	}
+
+	rsp, err := webserver.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return errors.New("failed to cancel restaurant order: " + rsp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package eats
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/message"
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/webserver"
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+)
+
+func init() {
+	cadence.RegisterActivity(AccruePointsActivity)
+	cadence.RegisterActivity(UpdateLoyaltyActivity)
+}
+
+// AccruePointsActivity implements the accrue points activity, which credits
+// the customer who placed the completed order with loyalty points.
+func AccruePointsActivity(ctx context.Context, orderID string) error {
+	url := webserver.URL + "/eats-loyalty?action=accrue&id=" + orderID
+	rsp, err := webserver.Client.Post(url, "text/plain", nil)
+	if err != nil {
+		cadence.GetActivityLogger(ctx).Info("Failed to accrue points.", zap.Error(err))
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return errors.New("failed to accrue points: " + rsp.Status)
+	}
+
+	cadence.GetActivityLogger(ctx).Info("Accrued points for order!", zap.String("order", orderID))
+	return nil
+}
+
+// UpdateLoyaltyActivity implements the update loyalty activity, which
+// publishes the points balance of the customer to the eats service.
+func UpdateLoyaltyActivity(ctx context.Context, customerID string, points int, nextExpiry time.Time) error {
+	data, err := json.Marshal(&message.LoyaltyBalance{
+		CustomerID: customerID,
+		Points:     points,
+		NextExpiry: nextExpiry,
+	})
+	if err != nil {
+		return err
+	}
+
+	url := webserver.URL + "/eats-loyalty?customer=" + customerID
+	req, err := http.NewRequest("PATCH", url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	rsp, err := webserver.Client.Do(req)
+	if err != nil {
+		cadence.GetActivityLogger(ctx).Info("Failed to update loyalty balance.", zap.Error(err))
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return errors.New("failed to update loyalty balance: " + rsp.Status)
+	}
+	return nil
+}
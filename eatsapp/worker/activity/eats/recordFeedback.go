@@ -0,0 +1,60 @@
+package eats
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/webserver"
+)
+
+func init() {
+	cadence.RegisterActivity(RecordFeedbackActivity)
+}
+
+// RecordFeedbackActivity implements the record feedback activity, which
+// stores the customer ratings with the delivery job and the restaurant order.
+func RecordFeedbackActivity(ctx context.Context, orderID string, courierRating int, restaurantRating int) error {
+	if courierRating > 0 {
+		err := rate("courier", orderID, courierRating)
+		if err != nil {
+			cadence.GetActivityLogger(ctx).Info("Failed to rate courier.", zap.Error(err))
+			return err
+		}
+	}
+
+	if restaurantRating > 0 {
+		err := rate("restaurant", orderID, restaurantRating)
+		if err != nil {
+			cadence.GetActivityLogger(ctx).Info("Failed to rate restaurant.", zap.Error(err))
+			return err
+		}
+	}
+
+	cadence.GetActivityLogger(ctx).Info("Recorded feedback for order!", zap.String("order", orderID))
+	return nil
+}
+
+func rate(service string, orderID string, rating int) error {
+	url := webserver.URL + "/" + service + "?action=rate&id=" + orderID +
+		"&rating=" + strconv.Itoa(rating)
+	req, err := http.NewRequest("PATCH", url, nil)
+	if err != nil {
+		return err
+	}
+
+	rsp, err := webserver.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return errors.New("failed to rate " + service + ": " + rsp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package eats
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/webserver"
+)
+
+func init() {
+	cadence.RegisterActivity(RepriceOrderActivity)
+}
+
+// RepriceOrderActivity implements the reprice order activity, which
+// updates the amount charged for an order modified by the restaurant.
+func RepriceOrderActivity(ctx context.Context, orderID string, originalTotal float32, total float32) error {
+	url := webserver.URL + "/eats-price?action=reprice&id=" + orderID +
+		"&total=" + strconv.FormatFloat(float64(total), 'f', -1, 32)
+	req, err := http.NewRequest("PATCH", url, nil)
+	if err != nil {
+		return err
+	}
+	rsp, err := webserver.Client.Do(req)
+	if err != nil {
+		cadence.GetActivityLogger(ctx).Info("Failed to reprice order.", zap.Error(err))
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return errors.New("failed to reprice order: " + rsp.Status)
+	}
+
+	cadence.GetActivityLogger(ctx).Info("Repriced order!", zap.String("order", orderID),
+		zap.Float32("original", originalTotal), zap.Float32("total", total))
+	return nil
+}
@@ -0,0 +1,58 @@
+package eats
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/webserver"
+)
+
+func init() {
+	cadence.RegisterActivity(RequestSubstitutionActivity)
+}
+
+// RequestSubstitutionActivity implements the request substitution activity,
+// which asks the customer to replace the items that became unavailable.
+func RequestSubstitutionActivity(ctx context.Context, execution cadence.WorkflowExecution, orderID string,
+	items []string, unavailable []string) error {
+	logger := cadence.GetActivityLogger(ctx)
+
+	err := requestSubstitution(execution, orderID, items, unavailable)
+	if err != nil {
+		logger.Info("Failed to request substitution.", zap.Error(err))
+		return err
+	}
+
+	logger.Info("Requested substitution from customer.", zap.String("Order ID", orderID), zap.Strings("Unavailable", unavailable))
+	return nil
+}
+
+func requestSubstitution(execution cadence.WorkflowExecution, orderID string, items []string, unavailable []string) error {
+	formData := url.Values{}
+	formData.Add("id", orderID)
+	formData.Add("workflow_id", execution.ID)
+	formData.Add("run_id", execution.RunID)
+	for _, item := range items {
+		formData.Add("item", item)
+	}
+	for _, item := range unavailable {
+		formData.Add("unavailable", item)
+	}
+
+	url := webserver.URL + "/eats-substitution"
+	rsp, err := webserver.Client.PostForm(url, formData)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return errors.New("failed to request substitution: " + rsp.Status)
+	}
+	return nil
+}
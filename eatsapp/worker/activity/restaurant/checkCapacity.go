@@ -0,0 +1,58 @@
+package restaurant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/webserver"
+)
+
+// Admission models the decision of the restaurant to accept, queue, schedule
+// or reject an order, based on its capacity and opening hours.
+type Admission struct {
+	Decision string
+	Reason   string
+	Delay    time.Duration // time a queued or scheduled order waits before it is prepared
+}
+
+func init() {
+	cadence.RegisterActivity(CheckCapacityActivity)
+}
+
+// CheckCapacityActivity implements the check restaurant capacity activity.
+func CheckCapacityActivity(ctx context.Context, orderID string) (Admission, error) {
+	admission, err := checkCapacity(orderID)
+	if err != nil {
+		cadence.GetActivityLogger(ctx).Info("Failed to check restaurant capacity.", zap.Error(err))
+		return Admission{}, err
+	}
+
+	cadence.GetActivityLogger(ctx).Info("Checked restaurant capacity", zap.String("decision", admission.Decision),
+		zap.String("reason", admission.Reason), zap.Duration("delay", admission.Delay))
+	return *admission, nil
+}
+
+func checkCapacity(orderID string) (*Admission, error) {
+	url := webserver.URL + "/restaurant-capacity?id=" + orderID
+	rsp, err := webserver.Client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return nil, errors.New("failed to check capacity: " + rsp.Status)
+	}
+
+	var admission Admission
+	if err := json.NewDecoder(rsp.Body).Decode(&admission); err != nil {
+		return nil, err
+	}
+	return &admission, nil
+}
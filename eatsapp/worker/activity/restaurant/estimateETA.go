@@ -2,16 +2,57 @@ package restaurant
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
 	"time"
 
 	"go.uber.org/cadence"
+	"go.uber.org/zap"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/webserver"
 )
 
+// estimate is the subset of the restaurant estimate used by the workflow.
+type estimate struct {
+	ETA         time.Duration
+	QueueLength int
+}
+
 func init() {
 	cadence.RegisterActivity(EstimateETAActivity)
 }
 
-// EstimateETAActivity implements the estimate eta activity.
+// EstimateETAActivity implements the estimate eta activity. The estimate
+// is based on the prep time of the items, the orders already being prepared
+// and the actual prep times of past orders.
 func EstimateETAActivity(ctx context.Context, orderID string) (time.Duration, error) {
-	return time.Minute, nil
+	est, err := getEstimate(orderID)
+	if err != nil {
+		cadence.GetActivityLogger(ctx).Info("Failed to estimate ETA.", zap.Error(err))
+		return 0, err
+	}
+
+	cadence.GetActivityLogger(ctx).Info("Computed restaurant ready ETA",
+		zap.Duration("ETA", est.ETA), zap.Int("queue", est.QueueLength))
+	return est.ETA, nil
+}
+
+func getEstimate(orderID string) (*estimate, error) {
+	url := webserver.URL + "/restaurant-eta?id=" + orderID
+	rsp, err := webserver.Client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return nil, errors.New("failed to estimate ETA: " + rsp.Status)
+	}
+
+	var est estimate
+	if err := json.NewDecoder(rsp.Body).Decode(&est); err != nil {
+		return nil, err
+	}
+	return &est, nil
 }
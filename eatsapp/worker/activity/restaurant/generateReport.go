@@ -0,0 +1,98 @@
+package restaurant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/webserver"
+)
+
+// SalesReport models the totals of the daily sales report of the restaurant.
+type SalesReport struct {
+	Date          string
+	Orders        int
+	Revenue       float32
+	RejectionRate float64
+}
+
+// ReportDay models the day of the next sales report, formatted as
+// YYYY-MM-DD, and the time the report is generated at.
+type ReportDay struct {
+	Date string
+	At   time.Time
+}
+
+// ReportDelay is the time after midnight the report of the previous day is
+// generated, which lets the orders placed late in the day complete.
+const ReportDelay = time.Hour * 2
+
+// ReportTimeZone is the time zone of the restaurant, set by the worker from
+// its configuration, the time zone of the worker if empty.
+var ReportTimeZone string
+
+func init() {
+	cadence.RegisterActivity(GenerateReportActivity)
+	cadence.RegisterActivity(GetReportDayActivity)
+}
+
+// GetReportDayActivity implements the get report day activity, which
+// returns the day of the next sales report in the time zone, or in the
+// ReportTimeZone if empty.
+func GetReportDayActivity(ctx context.Context, timeZone string) (ReportDay, error) {
+	if len(timeZone) == 0 {
+		timeZone = ReportTimeZone
+	}
+	loc := time.Local
+	if len(timeZone) > 0 {
+		var err error
+		if loc, err = time.LoadLocation(timeZone); err != nil {
+			cadence.GetActivityLogger(ctx).Info("Invalid restaurant time zone.", zap.String("zone", timeZone), zap.Error(err))
+			return ReportDay{}, err
+		}
+	}
+	return nextReportDay(time.Now().In(loc)), nil
+}
+
+// nextReportDay returns the day reported next at the given time, the
+// previous day until ReportDelay after midnight.
+func nextReportDay(now time.Time) ReportDay {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if now.Before(day.Add(ReportDelay)) {
+		day = day.AddDate(0, 0, -1)
+	}
+	return ReportDay{
+		Date: day.Format("2006-01-02"),
+		At:   day.AddDate(0, 0, 1).Add(ReportDelay),
+	}
+}
+
+// GenerateReportActivity implements the generate sales report activity,
+// date is the day of the report formatted as YYYY-MM-DD.
+func GenerateReportActivity(ctx context.Context, date string) (SalesReport, error) {
+	url := webserver.URL + "/restaurant-reports?date=" + date
+	rsp, err := webserver.Client.Post(url, "text/plain", nil)
+	if err != nil {
+		cadence.GetActivityLogger(ctx).Info("Failed to generate sales report.", zap.Error(err))
+		return SalesReport{}, err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return SalesReport{}, errors.New("failed to generate sales report: " + rsp.Status)
+	}
+
+	var report SalesReport
+	if err := json.NewDecoder(rsp.Body).Decode(&report); err != nil {
+		return SalesReport{}, err
+	}
+
+	cadence.GetActivityLogger(ctx).Info("Generated sales report.", zap.String("date", report.Date),
+		zap.Int("orders", report.Orders), zap.Float32("revenue", report.Revenue))
+	return report, nil
+}
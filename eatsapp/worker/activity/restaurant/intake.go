@@ -0,0 +1,76 @@
+package restaurant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/webserver"
+)
+
+// OpeningHours models the current, or next, opening interval of the
+// restaurant and whether its order intake is open.
+type OpeningHours struct {
+	Intake     bool
+	AlwaysOpen bool
+	OpensAt    time.Time
+	ClosesAt   time.Time
+}
+
+func init() {
+	cadence.RegisterActivity(GetOpeningHoursActivity)
+	cadence.RegisterActivity(SetIntakeActivity)
+}
+
+// GetOpeningHoursActivity implements the get opening hours activity.
+func GetOpeningHoursActivity(ctx context.Context) (OpeningHours, error) {
+	url := webserver.URL + "/restaurant-hours"
+	rsp, err := webserver.Client.Get(url)
+	if err != nil {
+		cadence.GetActivityLogger(ctx).Info("Failed to get opening hours.", zap.Error(err))
+		return OpeningHours{}, err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return OpeningHours{}, errors.New("failed to get opening hours: " + rsp.Status)
+	}
+
+	var hours OpeningHours
+	if err := json.NewDecoder(rsp.Body).Decode(&hours); err != nil {
+		return OpeningHours{}, err
+	}
+	return hours, nil
+}
+
+// SetIntakeActivity implements the set intake activity, which opens
+// or closes the order intake of the restaurant.
+func SetIntakeActivity(ctx context.Context, open bool) error {
+	action := "close"
+	if open {
+		action = "open"
+	}
+
+	req, err := http.NewRequest("PATCH", webserver.URL+"/restaurant-hours?action="+action, nil)
+	if err != nil {
+		return err
+	}
+	rsp, err := webserver.Client.Do(req)
+	if err != nil {
+		cadence.GetActivityLogger(ctx).Info("Failed to set order intake.", zap.Error(err))
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return errors.New("failed to set order intake: " + rsp.Status)
+	}
+
+	cadence.GetActivityLogger(ctx).Info("Set order intake.", zap.Bool("open", open))
+	return nil
+}
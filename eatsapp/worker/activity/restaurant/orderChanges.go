@@ -0,0 +1,93 @@
+package restaurant
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/webserver"
+)
+
+// OrderChanges models the changes made by the restaurant when it accepted
+// only part of an order, or substituted some of its items.
+type OrderChanges struct {
+	OrderID       string
+	Items         []string          // items the restaurant will prepare
+	Rejected      []string          // items removed from the order
+	Substitutions map[string]string // original item -> substitute
+	OriginalTotal float32
+	Total         float32
+}
+
+func init() {
+	cadence.RegisterActivity(GetOrderChangesActivity)
+	cadence.RegisterActivity(NotifyOrderChangesActivity)
+}
+
+// GetOrderChangesActivity implements the get order changes activity, which
+// returns the changes made by the restaurant when accepting the order.
+func GetOrderChangesActivity(ctx context.Context, orderID string) (OrderChanges, error) {
+	changes, err := getOrderChanges(orderID)
+	if err != nil {
+		cadence.GetActivityLogger(ctx).Info("Failed to get order changes.", zap.Error(err))
+		return OrderChanges{}, err
+	}
+	return *changes, nil
+}
+
+// NotifyOrderChangesActivity implements the notify order changes activity,
+// which asks the customer to confirm the order modified by the restaurant.
+func NotifyOrderChangesActivity(ctx context.Context, wfRunID string, changes OrderChanges) error {
+	err := notifyOrderChanges(wfRunID, &changes)
+	if err != nil {
+		cadence.GetActivityLogger(ctx).Info("Failed to notify order changes.", zap.Error(err))
+		return err
+	}
+
+	cadence.GetActivityLogger(ctx).Info("Notified customer of order changes.", zap.String("Order ID", changes.OrderID),
+		zap.Strings("Rejected", changes.Rejected))
+	return nil
+}
+
+func getOrderChanges(orderID string) (*OrderChanges, error) {
+	url := webserver.URL + "/restaurant-changes?id=" + orderID
+	rsp, err := webserver.Client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return nil, errors.New("failed to get order changes: " + rsp.Status)
+	}
+
+	var changes OrderChanges
+	if err := json.NewDecoder(rsp.Body).Decode(&changes); err != nil {
+		return nil, err
+	}
+	return &changes, nil
+}
+
+func notifyOrderChanges(wfRunID string, changes *OrderChanges) error {
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	url := webserver.URL + "/eats-changes?id=" + changes.OrderID + "&run_id=" + wfRunID
+	rsp, err := webserver.Client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return errors.New("failed to notify order changes: " + rsp.Status)
+	}
+	return nil
+}
@@ -2,13 +2,21 @@ package restaurant
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/url"
 
 	"go.uber.org/cadence"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/webserver"
 )
 
+// ItemsUnavailableReason is the reason of the error that fails the activity
+// when some items of the order are out of stock. The error details hold
+// the IDs of the unavailable items.
+const ItemsUnavailableReason = "ITEMS_UNAVAILABLE"
+
 func init() {
 	cadence.RegisterActivity(PlaceOrderActivity)
 }
@@ -27,10 +35,23 @@ func sendOrder(wfRunID string, orderID string, items []string, taskToken string)
 	for _, item := range items {
 		formData.Add("item", item)
 	}
-	url := "http://localhost:8090/restaurant"
-	_, err := http.PostForm(url, formData)
+	url := webserver.URL + "/restaurant"
+	rsp, err := webserver.Client.PostForm(url, formData)
 	if err != nil {
 		return err
 	}
+	defer rsp.Body.Close()
+
+	// the restaurant refuses orders with items that ran out
+	if rsp.StatusCode == http.StatusConflict {
+		var unavailable []string
+		if err := json.NewDecoder(rsp.Body).Decode(&unavailable); err != nil {
+			return err
+		}
+		return cadence.NewCustomError(ItemsUnavailableReason, unavailable)
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return errors.New("failed to send order: " + rsp.Status)
+	}
 	return nil
 }
@@ -0,0 +1,56 @@
+package restaurant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/webserver"
+)
+
+func init() {
+	cadence.RegisterActivity(SendTicketsActivity)
+}
+
+// SendTicketsActivity implements the send kitchen tickets activity. The
+// order is split into one ticket per kitchen station, based on the station
+// of each item in the menu, and the stations that got a ticket are returned.
+func SendTicketsActivity(ctx context.Context, orderID string) ([]string, error) {
+	logger := cadence.GetActivityLogger(ctx)
+
+	stations, err := sendTickets(orderID)
+	if err != nil {
+		logger.Info("Failed to send kitchen tickets.", zap.Error(err))
+		return nil, err
+	}
+
+	logger.Info("Successfully sent kitchen tickets.", zap.String("Order ID", orderID), zap.Strings("Stations", stations))
+	return stations, nil
+}
+
+func sendTickets(orderID string) ([]string, error) {
+	formData := url.Values{}
+	formData.Add("id", orderID)
+
+	url := webserver.URL + "/restaurant-kitchen"
+	rsp, err := webserver.Client.PostForm(url, formData)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return nil, errors.New("failed to send tickets: " + rsp.Status)
+	}
+
+	var stations []string
+	if err := json.NewDecoder(rsp.Body).Decode(&stations); err != nil {
+		return nil, err
+	}
+	return stations, nil
+}
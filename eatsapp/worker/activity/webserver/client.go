@@ -0,0 +1,48 @@
+// Package webserver holds the http client the activities send their
+// requests to the eats webserver with.
+package webserver
+
+import (
+	"net/http"
+)
+
+// serviceTransport authenticates the requests sent to the webserver with
+// the service token shared by the workers and the webserver.
+type serviceTransport struct {
+	token string
+	base  http.RoundTripper
+}
+
+var (
+	// URL is the URL of the webserver, see Configure.
+	URL = "http://localhost:8090"
+
+	// Client sends the requests of the activities to the webserver.
+	Client = &http.Client{}
+)
+
+// Configure points the activities at the webserver URL, authenticating
+// their requests with the service token if any. The other http clients
+// of the process are left as they are.
+func Configure(url string, token string) {
+	URL = url
+	Client = &http.Client{}
+	if len(token) > 0 {
+		Client.Transport = &serviceTransport{
+			token: token,
+			base:  http.DefaultTransport,
+		}
+	}
+}
+
+func (t *serviceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrip must not modify the request
+	clone := new(http.Request)
+	*clone = *req
+	clone.Header = make(http.Header, len(req.Header)+1)
+	for k, v := range req.Header {
+		clone.Header[k] = v
+	}
+	clone.Header.Set("Authorization", "Bearer "+t.token)
+	return t.base.RoundTrip(clone)
+}
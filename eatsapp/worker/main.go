@@ -1,27 +1,102 @@
 package main
 
 import (
+	"strings"
+	"time"
+
 	"github.com/venkat1109/cadence-codelab/common"
 	_ "github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/courier"
 	_ "github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/eats"
-	_ "github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/restaurant"
-	_ "github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/courier"
+	restaurantactivity "github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/restaurant"
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/webserver"
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/courier"
 	_ "github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/eats"
-	_ "github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/restaurant"
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/restaurant"
 	"go.uber.org/cadence"
+	"go.uber.org/zap"
 )
 
 const (
 	TaskListName = "cadence-bistro"
+
+	// PayoutWorkflowID is the ID of the singleton courier payout workflow.
+	PayoutWorkflowID = "courier-payouts"
+
+	// HoursWorkflowID is the ID of the singleton restaurant hours workflow.
+	HoursWorkflowID = "restaurant-hours"
+
+	// ReportWorkflowID is the ID of the singleton sales report workflow.
+	ReportWorkflowID = "restaurant-reports"
 )
 
 func main() {
 	runtime := common.NewRuntime()
+	webserverURL := runtime.Config.Webserver.URL()
+	webserver.Configure(webserverURL, runtime.Config.Auth.ServiceToken)
+	restaurantactivity.ReportTimeZone = runtime.Config.Restaurant.TimeZone
+
+	health := common.NewHealth()
+	health.AddCheck("cadence", runtime.CadenceCheck())
+	health.AddCheck("workers", runtime.WorkersCheck())
+	health.AddCheck("webserver", common.HTTPCheck(webserverURL+"/healthz"))
+	runtime.StartAdminServer(runtime.Config.Admin.EatsWorker, health)
+
 	// Configure worker options.
 	workerOptions := cadence.WorkerOptions{
 		MetricsScope: runtime.Scope,
 		Logger:       runtime.Logger,
 	}
 	runtime.StartWorkers(runtime.Config.DomainName, TaskListName, workerOptions)
+	startPayoutWorkflow(runtime)
+	startHoursWorkflow(runtime)
+	startReportWorkflow(runtime)
 	select {}
 }
+
+// startPayoutWorkflow starts the courier payout workflow
+// unless a previous worker has already started it.
+func startPayoutWorkflow(runtime *common.Runtime) {
+	schedule := &courier.PayoutSchedule{
+		Frequency: time.Hour,
+	}
+	startSingletonWorkflow(runtime, PayoutWorkflowID, schedule.ExecutionTimeout(), courier.PayoutWorkflow, schedule)
+}
+
+// startHoursWorkflow starts the restaurant hours workflow
+// unless a previous worker has already started it.
+func startHoursWorkflow(runtime *common.Runtime) {
+	startSingletonWorkflow(runtime, HoursWorkflowID, restaurant.HoursExecutionTimeout, restaurant.HoursWorkflow)
+}
+
+// startReportWorkflow starts the daily sales report workflow
+// unless a previous worker has already started it.
+func startReportWorkflow(runtime *common.Runtime) {
+	schedule := restaurant.ReportSchedule{Version: restaurant.ReportScheduleVersion}
+	startSingletonWorkflow(runtime, ReportWorkflowID, restaurant.ReportExecutionTimeout, restaurant.DailyReportWorkflow, schedule)
+}
+
+func startSingletonWorkflow(runtime *common.Runtime, workflowID string, timeout time.Duration,
+	workflow interface{}, args ...interface{}) {
+	client, err := runtime.Builder.BuildCadenceClient()
+	if err != nil {
+		panic(err)
+	}
+
+	workflowOptions := cadence.StartWorkflowOptions{
+		ID:                              workflowID,
+		TaskList:                        TaskListName,
+		ExecutionStartToCloseTimeout:    timeout,
+		DecisionTaskStartToCloseTimeout: time.Minute,
+	}
+
+	we, err := client.StartWorkflow(workflowOptions, workflow, args...)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "WorkflowExecutionAlreadyStartedError") {
+			runtime.Logger.Info("Workflow already running", zap.String("WorkflowID", workflowID))
+			return
+		}
+		runtime.Logger.Error("Failed to start workflow", zap.String("WorkflowID", workflowID), zap.Error(err))
+		return
+	}
+	runtime.Logger.Info("Started workflow", zap.String("WorkflowID", we.ID), zap.String("RunID", we.RunID))
+}
@@ -0,0 +1,19 @@
+package courier
+
+import (
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/courier"
+
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+)
+
+// postEarnings records the earnings for the delivery, or for the leg of
+// the delivery, in the courier ledger. A failure here must not fail the
+// delivery, so errors are only logged and the entry can be re-posted from
+// the ledger later.
+func postEarnings(ctx cadence.Context, orderID string, leg string) {
+	err := cadence.ExecuteActivity(ctx, courier.PostEarningsActivity, orderID, leg).Get(ctx, nil)
+	if err != nil {
+		cadence.GetLogger(ctx).Error("Failed to post courier earnings", zap.Error(err))
+	}
+}
@@ -0,0 +1,195 @@
+package courier
+
+import (
+	"time"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/message"
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/courier"
+
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+)
+
+type (
+	// DeliveryFailure is the detail of the error returned by the
+	// courier order workflow when an order could not be delivered.
+	DeliveryFailure struct {
+		Reason   string
+		Outcome  string
+		Attempts int
+	}
+)
+
+const (
+	// DeliveryFailedReason is the reason of the error returned by the
+	// workflow, the error details hold a DeliveryFailure.
+	DeliveryFailedReason = "DELIVERY_FAILED"
+
+	// CustomerResponseSignalName is the signal sent when the
+	// customer responds to a failed delivery attempt.
+	CustomerResponseSignalName = "CUSTOMER_RESPONSE"
+)
+
+// Values representing the reason of a failed delivery.
+const (
+	DFCustomerUnreachable = "CUSTOMER_UNREACHABLE"
+	DFWrongAddress        = "WRONG_ADDRESS"
+	DFAccessDenied        = "ACCESS_DENIED"
+	DFOrderDamaged        = "ORDER_DAMAGED"
+)
+
+// Values representing the response of a customer to a failed delivery.
+const (
+	CRRetry  = "RETRY"
+	CRReturn = "RETURN"
+)
+
+// Values representing the outcome of a failed delivery.
+const (
+	DOReturned = "RETURNED"
+	DODisposed = "DISPOSED"
+)
+
+const (
+	// waitAtDoorTimeout is the time the courier waits for the customer to respond.
+	waitAtDoorTimeout = time.Minute * 5
+	// maxDeliveryAttempts is the number of times delivery is attempted.
+	maxDeliveryAttempts = 2
+)
+
+// CustomerAtFault returns true if the delivery failed because of the customer.
+func (f *DeliveryFailure) CustomerAtFault() bool {
+	return f.Reason != DFOrderDamaged
+}
+
+// AsDeliveryFailure returns the DeliveryFailure carried by
+// the error returned from the courier order workflow.
+func AsDeliveryFailure(err error) (*DeliveryFailure, bool) {
+	customErr, ok := err.(*cadence.CustomError)
+	if !ok || customErr.Reason() != DeliveryFailedReason {
+		return nil, false
+	}
+
+	var failure DeliveryFailure
+	if err := customErr.Details(&failure); err != nil {
+		return nil, false
+	}
+	return &failure, true
+}
+
+// deliveryAttemptFailure returns the reason reported by the courier
+// when DeliverOrderActivity failed because of a failed delivery.
+func deliveryAttemptFailure(err error) (string, bool) {
+	customErr, ok := err.(*cadence.CustomError)
+	if !ok || customErr.Reason() != message.DeliveryAttemptFailedReason {
+		return "", false
+	}
+
+	var reason string
+	if err := customErr.Details(&reason); err != nil {
+		return "", false
+	}
+	return reason, true
+}
+
+// handleFailedDelivery waits at the door while the customer is contacted,
+// retrying delivery if the customer responds in time. If the order still
+// cannot be delivered, it is returned to the restaurant, or disposed if it
+// is damaged, and a DeliveryFailedReason error is returned.
+func handleFailedDelivery(ctx cadence.Context, orderID string, reason string) error {
+	logger := cadence.GetLogger(ctx)
+	attempts := 1
+
+	for reason != DFOrderDamaged && attempts < maxDeliveryAttempts {
+		logger.Info("Delivery failed, waiting for customer", zap.String("reason", reason))
+		if !waitAtDoor(ctx, orderID, reason) {
+			break
+		}
+
+		attempts++
+		err := cadence.ExecuteActivity(ctx, courier.UpdateDeliveryActivity, orderID, "retry").Get(ctx, nil)
+		if err != nil {
+			return err
+		}
+		err = cadence.ExecuteActivity(ctx, courier.DeliverOrderActivity, orderID).Get(ctx, nil)
+		if err == nil {
+			postEarnings(ctx, orderID, "")
+			return nil
+		}
+
+		var ok bool
+		if reason, ok = deliveryAttemptFailure(err); !ok {
+			logger.Error("Failed to complete delivery", zap.Error(err))
+			return err
+		}
+	}
+
+	failure := DeliveryFailure{
+		Reason:   reason,
+		Outcome:  DOReturned,
+		Attempts: attempts,
+	}
+	if reason == DFOrderDamaged {
+		failure.Outcome = DODisposed
+	}
+
+	err := resolveFailedDelivery(ctx, orderID, &failure)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Delivery failed", zap.String("reason", failure.Reason), zap.String("outcome", failure.Outcome))
+	return cadence.NewCustomError(DeliveryFailedReason, failure)
+}
+
+// waitAtDoor contacts the customer and waits for a response, returning
+// true if the customer asked for the delivery to be retried.
+func waitAtDoor(ctx cadence.Context, orderID string, reason string) bool {
+	execution := cadence.GetWorkflowInfo(ctx).WorkflowExecution
+	err := cadence.ExecuteActivity(ctx, courier.ContactCustomerActivity, execution, orderID, reason).Get(ctx, nil)
+	if err != nil {
+		// keep waiting, the customer may still show up at the door
+		cadence.GetLogger(ctx).Error("Failed to contact customer", zap.Error(err))
+	}
+
+	s := cadence.NewSelector(ctx)
+
+	ctx1, cancel := cadence.WithCancel(ctx)
+	timer := cadence.NewTimer(ctx1, waitAtDoorTimeout)
+	s.AddFuture(timer, func(f cadence.Future) {
+		f.Get(ctx, nil)
+	})
+
+	var response string
+	signalChan := cadence.GetSignalChannel(ctx, CustomerResponseSignalName)
+	s.AddReceive(signalChan, func(c cadence.Channel, more bool) {
+		c.Receive(ctx, &response)
+		cancel()
+		cadence.GetLogger(ctx).Info("Received customer response!", zap.String("value", response))
+	})
+	s.Select(ctx)
+
+	return response == CRRetry
+}
+
+// resolveFailedDelivery takes the order back to the restaurant, or has
+// the courier dispose of it, depending on the outcome of the failure.
+func resolveFailedDelivery(ctx cadence.Context, orderID string, failure *DeliveryFailure) error {
+	if failure.Outcome == DODisposed {
+		err := cadence.ExecuteActivity(ctx, courier.UpdateDeliveryActivity, orderID, "disposed").Get(ctx, nil)
+		if err != nil {
+			cadence.GetLogger(ctx).Error("Failed to dispose order", zap.Error(err))
+		}
+		return err
+	}
+
+	err := cadence.ExecuteActivity(ctx, courier.ReturnOrderActivity, orderID).Get(ctx, nil)
+	if err != nil {
+		cadence.GetLogger(ctx).Error("Failed to return order to restaurant", zap.Error(err))
+		return err
+	}
+
+	// the courier is paid for the return leg as well
+	postEarnings(ctx, orderID, message.ReturnLeg)
+	return nil
+}
@@ -0,0 +1,60 @@
+package courier
+
+import (
+	"time"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/message"
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/courier"
+
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+)
+
+type (
+	// PayoutSchedule is the input to the courier payout workflow.
+	PayoutSchedule struct {
+		Frequency time.Duration // frequency at which unpaid earnings are settled
+	}
+)
+
+// maxPayoutsPerRun limits the history size of a single payout workflow run.
+const maxPayoutsPerRun = 24
+
+func init() {
+	cadence.RegisterWorkflow(PayoutWorkflow)
+}
+
+// PayoutWorkflow periodically settles the unpaid earnings of every courier.
+func PayoutWorkflow(ctx cadence.Context, schedule *PayoutSchedule) error {
+
+	ao := cadence.ActivityOptions{
+		ScheduleToStartTimeout: time.Minute * 5,
+		StartToCloseTimeout:    time.Minute * 5,
+	}
+	ctx = cadence.WithActivityOptions(ctx, ao)
+
+	for i := 0; i < maxPayoutsPerRun; i++ {
+		err := cadence.Sleep(ctx, schedule.Frequency)
+		if err != nil {
+			return err
+		}
+
+		var payouts []message.Payout
+		err = cadence.ExecuteActivity(ctx, courier.SettleEarningsActivity).Get(ctx, &payouts)
+		if err != nil {
+			// unpaid entries stay in the ledger and are picked up next time
+			cadence.GetLogger(ctx).Error("Failed to settle courier earnings", zap.Error(err))
+			continue
+		}
+		cadence.GetLogger(ctx).Info("Settled courier earnings", zap.Int("payouts", len(payouts)))
+	}
+
+	// ContinueAsNew workflow to limit the history size
+	ctx = cadence.WithExecutionStartToCloseTimeout(ctx, schedule.ExecutionTimeout())
+	return cadence.NewContinueAsNewError(ctx, PayoutWorkflow, schedule)
+}
+
+// ExecutionTimeout returns the execution timeout for a single payout workflow run.
+func (s *PayoutSchedule) ExecutionTimeout() time.Duration {
+	return s.Frequency*maxPayoutsPerRun + time.Hour
+}
@@ -0,0 +1,193 @@
+package courier
+
+import (
+	"time"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/message"
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/courier"
+
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+)
+
+type (
+	// ShiftState models the state of a courier shift. It is
+	// carried over when the shift workflow continues as new.
+	ShiftState struct {
+		CourierID     string
+		Capacity      int
+		StartedAt     time.Time
+		OnBreak       bool
+		BreakStart    time.Time
+		Ending        bool
+		ActiveJobs    []string
+		JobsCompleted int
+		Breaks        int
+		BreakTime     time.Duration
+	}
+)
+
+// maxEventsPerShiftRun limits the history size of a single shift workflow run.
+const maxEventsPerShiftRun = 500
+
+func init() {
+	cadence.RegisterWorkflow(ShiftWorkflow)
+}
+
+// ShiftWorkflow tracks a courier from going online until going offline.
+// The courier availability is reported to the courier service after every
+// change, so that dispatch offers are only made to available couriers.
+func ShiftWorkflow(ctx cadence.Context, state *ShiftState) (*courier.ShiftReport, error) {
+
+	ao := cadence.ActivityOptions{
+		ScheduleToStartTimeout: time.Minute * 5,
+		StartToCloseTimeout:    time.Minute * 5,
+	}
+	ctx = cadence.WithActivityOptions(ctx, ao)
+
+	if state.StartedAt.IsZero() {
+		state.StartedAt = cadence.Now(ctx)
+		cadence.GetLogger(ctx).Info("Courier shift started", zap.String("courier", state.CourierID))
+	}
+	reportShift(ctx, state)
+
+	signalChan := cadence.GetSignalChannel(ctx, message.ShiftSignalName)
+	for i := 0; i < maxEventsPerShiftRun; i++ {
+		var event message.ShiftEvent
+		signalChan.Receive(ctx, &event)
+		applyEvent(ctx, state, event)
+		if state.done() {
+			return endShift(ctx, state), nil
+		}
+		reportShift(ctx, state)
+	}
+
+	// drain the events that arrived before continuing as new
+	var event message.ShiftEvent
+	for signalChan.ReceiveAsync(&event) {
+		applyEvent(ctx, state, event)
+		if state.done() {
+			return endShift(ctx, state), nil
+		}
+	}
+
+	// ContinueAsNew workflow to limit the history size
+	ctx = cadence.WithExecutionStartToCloseTimeout(ctx, message.ShiftWorkflowTimeout)
+	return nil, cadence.NewContinueAsNewError(ctx, ShiftWorkflow, state)
+}
+
+// endShift reports the shift summary and returns it as the workflow result.
+func endShift(ctx cadence.Context, state *ShiftState) *courier.ShiftReport {
+	report := state.report(cadence.Now(ctx))
+	err := cadence.ExecuteActivity(ctx, courier.ReportShiftActivity, report).Get(ctx, nil)
+	if err != nil {
+		cadence.GetLogger(ctx).Error("Failed to report shift summary", zap.Error(err))
+	}
+	cadence.GetLogger(ctx).Info("Courier shift ended", zap.String("courier", state.CourierID),
+		zap.Int("jobs", report.JobsCompleted), zap.Duration("breakTime", report.BreakTime))
+	return &report
+}
+
+// reportShift publishes the current availability of the courier.
+func reportShift(ctx cadence.Context, state *ShiftState) {
+	err := cadence.ExecuteActivity(ctx, courier.ReportShiftActivity, state.report(time.Time{})).Get(ctx, nil)
+	if err != nil {
+		cadence.GetLogger(ctx).Error("Failed to report shift status", zap.Error(err))
+	}
+}
+
+// applyEvent updates the shift state with the event, logging the jobs
+// that cannot be assigned to the courier.
+func applyEvent(ctx cadence.Context, state *ShiftState, event message.ShiftEvent) {
+	if !state.apply(event, cadence.Now(ctx)) {
+		cadence.GetLogger(ctx).Warn("Job rejected by courier shift", zap.String("courier", state.CourierID),
+			zap.String("order", event.OrderID), zap.String("status", state.status()))
+	}
+}
+
+// apply updates the shift state with the event received at the specified
+// time. It returns false when the event is a job assigned to a courier
+// that is on break, going offline or at capacity, which is not taken.
+func (s *ShiftState) apply(event message.ShiftEvent, now time.Time) bool {
+	switch event.Type {
+	case message.SEBreak:
+		if !s.OnBreak {
+			s.OnBreak = true
+			s.BreakStart = now
+			s.Breaks++
+		}
+	case message.SEResume:
+		if s.OnBreak {
+			s.OnBreak = false
+			s.BreakTime += now.Sub(s.BreakStart)
+		}
+	case message.SEOffline:
+		s.Ending = true
+	case message.SEJobAssigned:
+		if s.jobIndex(event.OrderID) >= 0 {
+			return true
+		}
+		if s.status() != message.CSAvailable {
+			return false
+		}
+		s.ActiveJobs = append(s.ActiveJobs, event.OrderID)
+	case message.SEJobCompleted, message.SEJobReleased:
+		if i := s.jobIndex(event.OrderID); i >= 0 {
+			s.ActiveJobs = append(s.ActiveJobs[:i], s.ActiveJobs[i+1:]...)
+			if event.Type == message.SEJobCompleted {
+				s.JobsCompleted++
+			}
+		}
+	}
+	return true
+}
+
+// done returns true once the courier asked to go offline and has no active jobs left.
+func (s *ShiftState) done() bool {
+	return s.Ending && len(s.ActiveJobs) == 0
+}
+
+func (s *ShiftState) status() string {
+	switch {
+	case s.done():
+		return message.CSOffline
+	case s.Ending:
+		return message.CSEnding
+	case s.OnBreak:
+		return message.CSOnBreak
+	case len(s.ActiveJobs) >= s.Capacity:
+		return message.CSBusy
+	default:
+		return message.CSAvailable
+	}
+}
+
+// report returns the shift report for the current state, endedAt
+// is only set once the shift is over.
+func (s *ShiftState) report(endedAt time.Time) courier.ShiftReport {
+	breakTime := s.BreakTime
+	if s.OnBreak && !endedAt.IsZero() {
+		breakTime += endedAt.Sub(s.BreakStart)
+	}
+	return courier.ShiftReport{
+		CourierID:     s.CourierID,
+		Status:        s.status(),
+		Capacity:      s.Capacity,
+		ActiveJobs:    len(s.ActiveJobs),
+		Jobs:          append([]string(nil), s.ActiveJobs...),
+		StartedAt:     s.StartedAt,
+		EndedAt:       endedAt,
+		JobsCompleted: s.JobsCompleted,
+		Breaks:        s.Breaks,
+		BreakTime:     breakTime,
+	}
+}
+
+func (s *ShiftState) jobIndex(orderID string) int {
+	for i, id := range s.ActiveJobs {
+		if id == orderID {
+			return i
+		}
+	}
+	return -1
+}
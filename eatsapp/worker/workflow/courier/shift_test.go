@@ -0,0 +1,62 @@
+package courier
+
+import (
+	"encoding/json"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/message"
+)
+
+func TestShiftWorkflowName(t *testing.T) {
+	name := runtime.FuncForPC(reflect.ValueOf(ShiftWorkflow).Pointer()).Name()
+	if name != message.ShiftWorkflowName {
+		t.Errorf("ShiftWorkflow is registered as %q, want %q", name, message.ShiftWorkflowName)
+	}
+}
+
+func TestShiftStartDecodesIntoShiftState(t *testing.T) {
+	data, err := json.Marshal(&message.ShiftStart{CourierID: "john", Capacity: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var state ShiftState
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatal(err)
+	}
+	if state.CourierID != "john" || state.Capacity != 2 {
+		t.Errorf("state = %+v, want courier john with capacity 2", state)
+	}
+}
+
+func TestShiftStateApplyJobAssigned(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name  string
+		state ShiftState
+		order string
+		want  bool
+		jobs  int
+	}{
+		{"available", ShiftState{Capacity: 2}, "o1", true, 1},
+		{"last slot", ShiftState{Capacity: 2, ActiveJobs: []string{"o1"}}, "o2", true, 2},
+		{"at capacity", ShiftState{Capacity: 2, ActiveJobs: []string{"o1", "o2"}}, "o3", false, 2},
+		{"on break", ShiftState{Capacity: 2, OnBreak: true}, "o1", false, 0},
+		{"going offline", ShiftState{Capacity: 2, Ending: true, ActiveJobs: []string{"o1"}}, "o2", false, 1},
+		{"already assigned", ShiftState{Capacity: 1, ActiveJobs: []string{"o1"}}, "o1", true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := tt.state
+			event := message.ShiftEvent{Type: message.SEJobAssigned, OrderID: tt.order}
+			if got := state.apply(event, now); got != tt.want {
+				t.Errorf("apply() = %v, want %v", got, tt.want)
+			}
+			if len(state.ActiveJobs) != tt.jobs {
+				t.Errorf("active jobs = %v, want %d", state.ActiveJobs, tt.jobs)
+			}
+		})
+	}
+}
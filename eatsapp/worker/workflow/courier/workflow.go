@@ -9,6 +9,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// dispatchRetryInterval is the time to wait before offering a job again.
+const dispatchRetryInterval = time.Second * 30
+
 func init() {
 	cadence.RegisterWorkflow(OrderWorkflow)
 }
@@ -25,8 +28,10 @@ func OrderWorkflow(ctx cadence.Context, orderID string) error {
 	for {
 		err := cadence.ExecuteActivity(ctx, courier.DispatchCourierActivity, orderID).Get(ctx, nil)
 		if err != nil {
-			// retry forever until a driver accepts the trip
+			// retry forever until a driver accepts the trip, backing off
+			// while couriers are offline, on break or at capacity
 			cadence.GetLogger(ctx).Error("Failed to dispatch courier", zap.Error(err))
+			cadence.Sleep(ctx, dispatchRetryInterval)
 			continue
 		}
 		break
@@ -47,9 +52,13 @@ func OrderWorkflow(ctx cadence.Context, orderID string) error {
 
 	err = cadence.ExecuteActivity(ctx, courier.DeliverOrderActivity, orderID).Get(ctx, nil)
 	if err != nil {
+		if reason, ok := deliveryAttemptFailure(err); ok {
+			return handleFailedDelivery(ctx, orderID, reason)
+		}
 		cadence.GetLogger(ctx).Error("Failed to complete delivery", zap.Error(err))
 		return err
 	}
 
+	postEarnings(ctx, orderID, "")
 	return nil
 }
@@ -0,0 +1,94 @@
+package eats
+
+import (
+	"time"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/eats"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/message"
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+)
+
+type (
+	// OrderChanges models the items rejected or substituted by the
+	// restaurant when it accepted only part of the order.
+	OrderChanges struct {
+		OrderID       string
+		Items         []string
+		Rejected      []string
+		Substitutions map[string]string
+		OriginalTotal float32
+		Total         float32
+	}
+)
+
+const (
+	// OrderChangesSignalName is the signal sent when the restaurant
+	// accepts the order with some of its items rejected or substituted.
+	OrderChangesSignalName = "ORDER_CHANGES"
+
+	// ConfirmChangesSignalName is the signal sent when the customer
+	// accepts (true) or declines (false) the modified order.
+	ConfirmChangesSignalName = "CONFIRM_CHANGES"
+)
+
+// confirmChangesTimeout is the time the customer has to decline
+// the modified order, after which it goes ahead as modified.
+const confirmChangesTimeout = time.Minute * 5
+
+// confirmOrderChanges asks the customer to confirm the order if the
+// restaurant modified it, and re-prices the order once confirmed. It
+// returns the items that are being prepared by the restaurant.
+func confirmOrderChanges(ctx cadence.Context, orderID string, items []string) ([]string, error) {
+	// the restaurant reports the changes before the restaurant
+	// order workflow completes, so the signal is already received
+	var changes OrderChanges
+	if !cadence.GetSignalChannel(ctx, OrderChangesSignalName).ReceiveAsync(&changes) {
+		return items, nil
+	}
+
+	logger := cadence.GetLogger(ctx)
+	logger.Info("Restaurant modified order, asking customer to confirm",
+		zap.Strings("items", changes.Items), zap.Strings("rejected", changes.Rejected))
+
+	s := cadence.NewSelector(ctx)
+
+	ctx1, cancel := cadence.WithCancel(ctx)
+	timer := cadence.NewTimer(ctx1, confirmChangesTimeout)
+	s.AddFuture(timer, func(f cadence.Future) {
+		f.Get(ctx, nil)
+	})
+
+	accepted := true
+	signalChan := cadence.GetSignalChannel(ctx, ConfirmChangesSignalName)
+	s.AddReceive(signalChan, func(c cadence.Channel, more bool) {
+		c.Receive(ctx, &accepted)
+		cancel()
+		logger.Info("Received order changes confirmation!", zap.Bool("accepted", accepted))
+	})
+	s.Select(ctx)
+
+	ao := cadence.ActivityOptions{
+		ScheduleToStartTimeout: time.Minute * 5,
+		StartToCloseTimeout:    time.Minute * 5,
+	}
+	ctx = cadence.WithActivityOptions(ctx, ao)
+
+	if !accepted {
+		err := cadence.ExecuteActivity(ctx, eats.CancelRestaurantOrderActivity, orderID).Get(ctx, nil)
+		if err != nil {
+			logger.Error("Failed to cancel restaurant order", zap.Error(err))
+			return nil, err
+		}
+		logger.Info("Order cancelled", zap.String("order", orderID))
+		return nil, cadence.NewCustomError(message.OrderCancelledReason)
+	}
+
+	err := cadence.ExecuteActivity(ctx, eats.RepriceOrderActivity, orderID, changes.OriginalTotal, changes.Total).Get(ctx, nil)
+	if err != nil {
+		logger.Error("Failed to reprice order", zap.Error(err))
+		return nil, err
+	}
+	return changes.Items, nil
+}
@@ -0,0 +1,39 @@
+package eats
+
+import (
+	"time"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/message"
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+)
+
+// waitForOrderReady waits for the restaurant until the order is ready. If
+// the ETA passes without a ready signal but the restaurant published a later
+// ETA in the meantime, it keeps waiting until the revised ETA.
+func waitForOrderReady(ctx cadence.Context, orderID string, eta time.Duration) error {
+	etaChan := cadence.GetSignalChannel(ctx, message.ETASignalName)
+
+	for eta > 0 {
+		start := cadence.Now(ctx)
+		err := waitForRestaurant(ctx, orderID, eta)
+		if err != nil {
+			return err
+		}
+		if cadence.Now(ctx).Sub(start) < eta {
+			// woken up by the ready signal
+			return nil
+		}
+
+		// the ETA passed, keep the most recent revision if any
+		eta = 0
+		var update message.ETAUpdate
+		for etaChan.ReceiveAsync(&update) {
+			eta = update.ReadyAt.Sub(cadence.Now(ctx))
+		}
+		if eta > 0 {
+			cadence.GetLogger(ctx).Info("Restaurant revised ETA", zap.Duration("remaining", eta))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package eats
+
+import (
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/courier"
+
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+)
+
+// settleFailedDelivery decides whether the customer pays for an order that
+// could not be delivered. Customers pay for orders they failed to receive,
+// orders that failed for any other reason are not charged, as orders are
+// only charged once delivered. The delivery failure is returned as the
+// workflow error either way.
+func settleFailedDelivery(ctx cadence.Context, orderID string, failure *courier.DeliveryFailure) error {
+	if failure.CustomerAtFault() {
+		err := chargeOrder(ctx, orderID)
+		if err != nil {
+			return err
+		}
+	}
+
+	cadence.GetLogger(ctx).Info("Order delivery failed", zap.String("order", orderID),
+		zap.String("reason", failure.Reason), zap.String("outcome", failure.Outcome),
+		zap.Bool("charged", failure.CustomerAtFault()))
+	return cadence.NewCustomError(courier.DeliveryFailedReason, *failure)
+}
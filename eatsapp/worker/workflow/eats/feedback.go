@@ -0,0 +1,65 @@
+package eats
+
+import (
+	"time"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/eats"
+
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+)
+
+type (
+	// Feedback models the ratings given by the customer once
+	// the order was delivered. A zero rating means not rated.
+	Feedback struct {
+		CourierRating    int
+		RestaurantRating int
+	}
+)
+
+// FeedbackSignalName is the signal sent when the customer rates an order.
+const FeedbackSignalName = "FEEDBACK"
+
+// feedbackTimeout is the time the customer has to rate the order.
+const feedbackTimeout = time.Minute * 10
+
+// collectFeedback waits for the customer to rate the delivered order and
+// records the ratings, which are used to rank couriers during dispatch.
+// Orders that are not rated in time complete without feedback.
+func collectFeedback(ctx cadence.Context, orderID string) {
+	s := cadence.NewSelector(ctx)
+
+	ctx1, cancel := cadence.WithCancel(ctx)
+	timer := cadence.NewTimer(ctx1, feedbackTimeout)
+	s.AddFuture(timer, func(f cadence.Future) {
+		f.Get(ctx, nil)
+	})
+
+	var feedback *Feedback
+	signalChan := cadence.GetSignalChannel(ctx, FeedbackSignalName)
+	s.AddReceive(signalChan, func(c cadence.Channel, more bool) {
+		feedback = &Feedback{}
+		c.Receive(ctx, feedback)
+		cancel()
+		cadence.GetLogger(ctx).Info("Received customer feedback!",
+			zap.Int("courier", feedback.CourierRating), zap.Int("restaurant", feedback.RestaurantRating))
+	})
+	s.Select(ctx)
+
+	if feedback == nil {
+		return
+	}
+
+	ao := cadence.ActivityOptions{
+		ScheduleToStartTimeout: time.Minute * 5,
+		StartToCloseTimeout:    time.Minute * 5,
+	}
+	ctx = cadence.WithActivityOptions(ctx, ao)
+	err := cadence.ExecuteActivity(ctx, eats.RecordFeedbackActivity,
+		orderID, feedback.CourierRating, feedback.RestaurantRating).Get(ctx, nil)
+	if err != nil {
+		// the order was delivered and paid for, a lost rating is not an order failure
+		cadence.GetLogger(ctx).Error("Failed to record feedback", zap.Error(err))
+	}
+}
@@ -0,0 +1,216 @@
+package eats
+
+import (
+	"time"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/eats"
+
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+)
+
+type (
+	// LoyaltyAccount models the loyalty points of a customer, it is
+	// the input of the loyalty workflow and carried over runs.
+	LoyaltyAccount struct {
+		CustomerID   string
+		PointsExpiry time.Duration
+		Batches      []*PointsBatch // oldest first
+	}
+
+	// PointsBatch models the points earned on a single order.
+	PointsBatch struct {
+		OrderID   string
+		Points    int
+		ExpiresAt time.Time
+	}
+
+	// PointsChange models the points earned or redeemed on an order.
+	PointsChange struct {
+		OrderID string
+		Points  int
+	}
+)
+
+const (
+	// AccruePointsSignalName is the signal sent when an order completes.
+	AccruePointsSignalName = "ACCRUE_POINTS"
+
+	// RedeemPointsSignalName is the signal sent when points are redeemed.
+	RedeemPointsSignalName = "REDEEM_POINTS"
+
+	// LoyaltyExecutionTimeout is the execution timeout of a single loyalty workflow run.
+	LoyaltyExecutionTimeout = loyaltyRunDuration + time.Hour*24
+)
+
+const (
+	// loyaltyRunDuration and maxPointsChanges limit the history size
+	// of a single loyalty workflow run.
+	loyaltyRunDuration = time.Hour * 24 * 30
+	maxPointsChanges   = 100
+)
+
+func init() {
+	cadence.RegisterWorkflow(LoyaltyWorkflow)
+}
+
+// LoyaltyWorkflowID returns the ID of the loyalty workflow of the customer.
+func LoyaltyWorkflowID(customerID string) string {
+	return "LOYALTY_" + customerID
+}
+
+// LoyaltyWorkflow keeps the loyalty points balance of a customer. Points are
+// accrued when orders complete, redeemed as discounts on new orders, and
+// expire after PointsExpiry, using a durable timer for the next expiry.
+// The balance is published to the eats service after every change.
+func LoyaltyWorkflow(ctx cadence.Context, account *LoyaltyAccount) error {
+
+	ao := cadence.ActivityOptions{
+		ScheduleToStartTimeout: time.Minute * 5,
+		StartToCloseTimeout:    time.Minute * 5,
+	}
+	ctx = cadence.WithActivityOptions(ctx, ao)
+	logger := cadence.GetLogger(ctx)
+
+	accrueChan := cadence.GetSignalChannel(ctx, AccruePointsSignalName)
+	redeemChan := cadence.GetSignalChannel(ctx, RedeemPointsSignalName)
+	runEnd := cadence.Now(ctx).Add(loyaltyRunDuration)
+
+	for changes := 0; changes < maxPointsChanges && cadence.Now(ctx).Before(runEnd); {
+		wake := runEnd
+		if next := account.nextExpiry(); !next.IsZero() && next.Before(wake) {
+			wake = next
+		}
+
+		s := cadence.NewSelector(ctx)
+
+		ctx1, cancel := cadence.WithCancel(ctx)
+		timer := cadence.NewTimer(ctx1, wake.Sub(cadence.Now(ctx)))
+		s.AddFuture(timer, func(f cadence.Future) {
+			f.Get(ctx, nil)
+		})
+		s.AddReceive(accrueChan, func(c cadence.Channel, more bool) {
+			var change PointsChange
+			c.Receive(ctx, &change)
+			cancel()
+			account.accrue(&change, cadence.Now(ctx))
+			logger.Info("Accrued points", zap.String("order", change.OrderID), zap.Int("points", change.Points))
+		})
+		s.AddReceive(redeemChan, func(c cadence.Channel, more bool) {
+			var change PointsChange
+			c.Receive(ctx, &change)
+			cancel()
+			redeemed := account.redeem(change.Points)
+			logger.Info("Redeemed points", zap.String("order", change.OrderID), zap.Int("points", redeemed))
+		})
+		s.Select(ctx)
+		changes++
+
+		if expired := account.expire(cadence.Now(ctx)); expired > 0 {
+			logger.Info("Points expired", zap.Int("points", expired))
+		}
+		publishBalance(ctx, account)
+	}
+
+	// signals received after the last select would be lost on continue as new
+	for {
+		var change PointsChange
+		if accrueChan.ReceiveAsync(&change) {
+			account.accrue(&change, cadence.Now(ctx))
+			continue
+		}
+		if redeemChan.ReceiveAsync(&change) {
+			account.redeem(change.Points)
+			continue
+		}
+		break
+	}
+	publishBalance(ctx, account)
+
+	// ContinueAsNew workflow to limit the history size
+	ctx = cadence.WithExecutionStartToCloseTimeout(ctx, LoyaltyExecutionTimeout)
+	return cadence.NewContinueAsNewError(ctx, LoyaltyWorkflow, account)
+}
+
+// accruePoints credits the customer with loyalty points for the completed
+// order, the points are computed by the eats service from the order price.
+func accruePoints(ctx cadence.Context, orderID string) {
+	ao := cadence.ActivityOptions{
+		ScheduleToStartTimeout: time.Minute * 5,
+		StartToCloseTimeout:    time.Minute * 5,
+	}
+	ctx = cadence.WithActivityOptions(ctx, ao)
+	err := cadence.ExecuteActivity(ctx, eats.AccruePointsActivity, orderID).Get(ctx, nil)
+	if err != nil {
+		// the order was delivered and paid for, lost points are not an order failure
+		cadence.GetLogger(ctx).Error("Failed to accrue points", zap.Error(err))
+	}
+}
+
+// publishBalance sends the balance of the account to the eats service,
+// where it is checked when customers redeem points.
+func publishBalance(ctx cadence.Context, account *LoyaltyAccount) {
+	err := cadence.ExecuteActivity(ctx, eats.UpdateLoyaltyActivity,
+		account.CustomerID, account.balance(), account.nextExpiry()).Get(ctx, nil)
+	if err != nil {
+		// the balance is published again on the next change
+		cadence.GetLogger(ctx).Error("Failed to publish loyalty balance", zap.Error(err))
+	}
+}
+
+// accrue adds the points earned on the order, they expire PointsExpiry later.
+func (a *LoyaltyAccount) accrue(change *PointsChange, now time.Time) {
+	if change.Points <= 0 {
+		return
+	}
+	a.Batches = append(a.Batches, &PointsBatch{
+		OrderID:   change.OrderID,
+		Points:    change.Points,
+		ExpiresAt: now.Add(a.PointsExpiry),
+	})
+}
+
+// redeem takes the points from the batches that expire first and
+// returns the number of points redeemed, capped by the balance.
+func (a *LoyaltyAccount) redeem(points int) int {
+	redeemed := 0
+	for len(a.Batches) > 0 && redeemed < points {
+		batch := a.Batches[0]
+		take := points - redeemed
+		if take >= batch.Points {
+			take = batch.Points
+			a.Batches = a.Batches[1:]
+		} else {
+			batch.Points -= take
+		}
+		redeemed += take
+	}
+	return redeemed
+}
+
+// expire removes the batches that expired and returns the points lost.
+func (a *LoyaltyAccount) expire(now time.Time) int {
+	expired := 0
+	for len(a.Batches) > 0 && !a.Batches[0].ExpiresAt.After(now) {
+		expired += a.Batches[0].Points
+		a.Batches = a.Batches[1:]
+	}
+	return expired
+}
+
+// balance returns the points available.
+func (a *LoyaltyAccount) balance() int {
+	points := 0
+	for _, batch := range a.Batches {
+		points += batch.Points
+	}
+	return points
+}
+
+// nextExpiry returns the time the oldest batch expires, if any.
+func (a *LoyaltyAccount) nextExpiry() time.Time {
+	if len(a.Batches) == 0 {
+		return time.Time{}
+	}
+	return a.Batches[0].ExpiresAt
+}
@@ -0,0 +1,58 @@
+package eats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoyaltyAccountRedeem(t *testing.T) {
+	now := time.Date(2017, 11, 20, 12, 0, 0, 0, time.UTC)
+	account := func(points ...int) *LoyaltyAccount {
+		a := &LoyaltyAccount{CustomerID: "joe", PointsExpiry: time.Hour}
+		for i, p := range points {
+			a.accrue(&PointsChange{OrderID: "o", Points: p}, now.Add(time.Minute*time.Duration(i)))
+		}
+		return a
+	}
+
+	tests := []struct {
+		name     string
+		account  *LoyaltyAccount
+		points   int
+		redeemed int
+		batches  []int // points left in every batch, first to expire first
+	}{
+		{"oldest batch first", account(30, 50), 20, 20, []int{10, 50}},
+		{"whole batch", account(30, 50), 30, 30, []int{50}},
+		{"across batches", account(30, 50), 40, 40, []int{40}},
+		{"capped by the balance", account(30, 50), 100, 80, nil},
+		{"empty account", account(), 10, 0, nil},
+		{"nothing to redeem", account(30), 0, 0, []int{30}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if redeemed := tt.account.redeem(tt.points); redeemed != tt.redeemed {
+				t.Errorf("redeem(%d) = %d, want %d", tt.points, redeemed, tt.redeemed)
+			}
+			if len(tt.account.Batches) != len(tt.batches) {
+				t.Fatalf("got %d batches left, want %d", len(tt.account.Batches), len(tt.batches))
+			}
+			for i, batch := range tt.account.Batches {
+				if batch.Points != tt.batches[i] {
+					t.Errorf("batch %d has %d points, want %d", i, batch.Points, tt.batches[i])
+				}
+			}
+			if balance := tt.account.balance(); balance != sum(tt.batches) {
+				t.Errorf("balance() = %d, want %d", balance, sum(tt.batches))
+			}
+		})
+	}
+}
+
+func sum(values []int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
@@ -0,0 +1,133 @@
+package eats
+
+import (
+	"time"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/eats"
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/restaurant"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/message"
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+)
+
+type (
+	// Substitution models the response of the customer to items that
+	// became unavailable, either the new list of items or a cancellation.
+	Substitution struct {
+		Items  []string
+		Cancel bool
+	}
+)
+
+const (
+	// SubstitutionSignalName is the signal sent when the customer
+	// responds to a substitution request.
+	SubstitutionSignalName = "SUBSTITUTION"
+)
+
+const (
+	// substitutionTimeout is the time the customer has to respond, after
+	// which the order goes ahead without the unavailable items.
+	substitutionTimeout = time.Minute * 10
+	// maxSubstitutions limits the number of times an order is sent back.
+	maxSubstitutions = 3
+)
+
+// placeOrderWithSubstitutions places the order with the restaurant, asking
+// the customer to substitute items that are out of stock. It returns the
+// restaurant ETA and the items that were eventually ordered.
+func placeOrderWithSubstitutions(ctx cadence.Context, orderID string, items []string) (time.Duration, []string, error) {
+	for i := 0; ; i++ {
+		eta, err := placeRestaurantOrder(ctx, orderID, items)
+		unavailable, ok := itemsUnavailable(err)
+		if !ok || i >= maxSubstitutions {
+			return eta, items, err
+		}
+
+		items, err = substituteItems(ctx, orderID, items, unavailable)
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+}
+
+// substituteItems asks the customer to replace the unavailable items and
+// returns the new list of items. If the customer does not respond in time
+// the unavailable items are dropped, and the order is cancelled if no
+// items are left.
+func substituteItems(ctx cadence.Context, orderID string, items []string, unavailable []string) ([]string, error) {
+	logger := cadence.GetLogger(ctx)
+	logger.Info("Items unavailable, asking customer for substitution", zap.Strings("items", unavailable))
+
+	ao := cadence.ActivityOptions{
+		ScheduleToStartTimeout: time.Minute * 5,
+		StartToCloseTimeout:    time.Minute * 5,
+	}
+	actx := cadence.WithActivityOptions(ctx, ao)
+	execution := cadence.GetWorkflowInfo(ctx).WorkflowExecution
+	err := cadence.ExecuteActivity(actx, eats.RequestSubstitutionActivity, execution, orderID, items, unavailable).Get(ctx, nil)
+	if err != nil {
+		// keep waiting, the customer can still respond from the order page
+		logger.Error("Failed to request substitution", zap.Error(err))
+	}
+
+	s := cadence.NewSelector(ctx)
+
+	ctx1, cancel := cadence.WithCancel(ctx)
+	timer := cadence.NewTimer(ctx1, substitutionTimeout)
+	s.AddFuture(timer, func(f cadence.Future) {
+		f.Get(ctx, nil)
+	})
+
+	var response *Substitution
+	signalChan := cadence.GetSignalChannel(ctx, SubstitutionSignalName)
+	s.AddReceive(signalChan, func(c cadence.Channel, more bool) {
+		response = &Substitution{}
+		c.Receive(ctx, response)
+		cancel()
+		logger.Info("Received substitution!", zap.Strings("items", response.Items), zap.Bool("cancel", response.Cancel))
+	})
+	s.Select(ctx)
+
+	if response == nil {
+		response = &Substitution{Items: without(items, unavailable)}
+	}
+	if response.Cancel || len(response.Items) == 0 {
+		logger.Info("Order cancelled", zap.String("order", orderID))
+		return nil, cadence.NewCustomError(message.OrderCancelledReason)
+	}
+	return response.Items, nil
+}
+
+// itemsUnavailable returns the items reported out of stock by the restaurant.
+func itemsUnavailable(err error) ([]string, bool) {
+	customErr, ok := err.(*cadence.CustomError)
+	if !ok || customErr.Reason() != restaurant.ItemsUnavailableReason {
+		return nil, false
+	}
+
+	var unavailable []string
+	if err := customErr.Details(&unavailable); err != nil {
+		return nil, false
+	}
+	return unavailable, true
+}
+
+// without returns the items that are not in the excluded list.
+func without(items []string, excluded []string) []string {
+	var result []string
+	for _, item := range items {
+		skip := false
+		for _, e := range excluded {
+			if item == e {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -1,6 +1,12 @@
 package eats
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/workflow/courier"
+
 	"go.uber.org/cadence"
 	"go.uber.org/zap"
 )
@@ -9,23 +15,32 @@ func init() {
 	cadence.RegisterWorkflow(OrderWorkflow)
 }
 
-// OrderWorkflow implements the eats order workflow.
-func OrderWorkflow(ctx cadence.Context, orderID string, items []string) error {
+// OrderWorkflow implements the eats order workflow of the customer.
+func OrderWorkflow(ctx cadence.Context, orderID string, customerID string, items []string) error {
+
+	cadence.GetLogger(ctx).Info("Received order",
+		zap.String("customer", customerID), zap.Strings("items", items))
 
-	cadence.GetLogger(ctx).Info("Received order", zap.Strings("items", items))
+	restaurantEta, items, err := placeOrderWithSubstitutions(ctx, orderID, items)
+	if err != nil {
+		return err
+	}
 
-	restaurantEta, err := placeRestaurantOrder(ctx, orderID, items)
+	items, err = confirmOrderChanges(ctx, orderID, items)
 	if err != nil {
 		return err
 	}
 
-	err = waitForRestaurant(ctx, orderID, restaurantEta)
+	err = waitForOrderReady(ctx, orderID, restaurantEta)
 	if err != nil {
 		return err
 	}
 
 	err = deliverOrder(ctx, orderID)
 	if err != nil {
+		if failure, ok := courier.AsDeliveryFailure(err); ok {
+			return settleFailedDelivery(ctx, orderID, failure)
+		}
 		return err
 	}
 
@@ -34,6 +49,22 @@ func OrderWorkflow(ctx cadence.Context, orderID string, items []string) error {
 		return err
 	}
 
+	accruePoints(ctx, orderID)
+
+	collectFeedback(ctx, orderID)
+
 	cadence.GetLogger(ctx).Info("Completed order", zap.String("order", orderID))
 	return nil
 }
+
+// NewOrderID returns the ID of an order placed by the customer at the given
+// time, which is also the ID of its workflow. The customer is part of the
+// ID so that the workflows of a customer can be told apart when listed.
+func NewOrderID(customerID string, t time.Time) string {
+	return fmt.Sprintf("%s%v", OrderIDPrefix(customerID), t.Unix())
+}
+
+// OrderIDPrefix returns the prefix of the IDs of the customer orders.
+func OrderIDPrefix(customerID string) string {
+	return fmt.Sprintf("EO-USR-%s-", strings.ToUpper(customerID))
+}
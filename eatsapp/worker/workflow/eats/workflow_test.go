@@ -0,0 +1,16 @@
+package eats
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/message"
+)
+
+func TestOrderWorkflowName(t *testing.T) {
+	name := runtime.FuncForPC(reflect.ValueOf(OrderWorkflow).Pointer()).Name()
+	if name != message.OrderWorkflowName {
+		t.Errorf("OrderWorkflow is registered as %q, want %q", name, message.OrderWorkflowName)
+	}
+}
@@ -0,0 +1,69 @@
+package restaurant
+
+import (
+	"time"
+
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/restaurant"
+)
+
+// Values representing the admission decision of the restaurant.
+const (
+	ADAccept   = "ACCEPT"
+	ADQueue    = "QUEUE"
+	ADSchedule = "SCHEDULE"
+	ADReject   = "REJECT"
+)
+
+// Values representing the reason an order was queued or rejected. A
+// rejected order fails the workflow with a custom error of that reason.
+const (
+	ARClosed      = "RESTAURANT_CLOSED"
+	ARBusy        = "RESTAURANT_BUSY"
+	ARHourlyLimit = "RESTAURANT_HOURLY_LIMIT"
+)
+
+// ItemsUnavailableReason is the reason of the error returned when items
+// of the order are out of stock, the error details hold their IDs.
+const ItemsUnavailableReason = restaurant.ItemsUnavailableReason
+
+// minScheduleDelay keeps scheduled orders from polling a closed restaurant.
+const minScheduleDelay = time.Minute
+
+// admitOrder checks the order against the restaurant capacity before it is
+// placed. Queued orders are placed right away, the kitchen ETA accounts for
+// the orders ahead of them. Orders placed while the restaurant is closed may be scheduled, in which
+// case they wait for the restaurant to open and are checked again.
+func admitOrder(ctx cadence.Context, orderID string) error {
+	for {
+		var admission restaurant.Admission
+		err := cadence.ExecuteActivity(ctx, restaurant.CheckCapacityActivity, orderID).Get(ctx, &admission)
+		if err != nil {
+			cadence.GetLogger(ctx).Error("Failed to check restaurant capacity", zap.Error(err))
+			return err
+		}
+
+		switch admission.Decision {
+		case ADReject:
+			cadence.GetLogger(ctx).Info("Restaurant rejected order", zap.String("reason", admission.Reason))
+			return cadence.NewCustomError(admission.Reason)
+		case ADQueue:
+			cadence.GetLogger(ctx).Info("Restaurant queued order", zap.String("reason", admission.Reason),
+				zap.Duration("delay", admission.Delay))
+			return nil
+		case ADSchedule:
+			cadence.GetLogger(ctx).Info("Restaurant closed, scheduled order", zap.Duration("delay", admission.Delay))
+			delay := admission.Delay
+			if delay < minScheduleDelay {
+				delay = minScheduleDelay
+			}
+			if err := cadence.Sleep(ctx, delay); err != nil {
+				return err
+			}
+			continue
+		}
+		return nil
+	}
+}
@@ -0,0 +1,33 @@
+package restaurant
+
+import (
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/restaurant"
+)
+
+// OrderModified is the result of PlaceOrderActivity when the restaurant
+// accepted the order with some items rejected or substituted.
+const OrderModified = "MODIFIED"
+
+// reportOrderChanges fetches the changes the restaurant made to the order
+// and forwards them to the eats.OrderWorkflow, which asks the customer to
+// confirm the modified order.
+func reportOrderChanges(ctx cadence.Context, wfRunID string, orderID string) error {
+	var changes restaurant.OrderChanges
+	err := cadence.ExecuteActivity(ctx, restaurant.GetOrderChangesActivity, orderID).Get(ctx, &changes)
+	if err != nil {
+		cadence.GetLogger(ctx).Error("Failed to get order changes", zap.Error(err))
+		return err
+	}
+
+	cadence.GetLogger(ctx).Info("Restaurant modified order", zap.Strings("items", changes.Items),
+		zap.Strings("rejected", changes.Rejected))
+	err = cadence.ExecuteActivity(ctx, restaurant.NotifyOrderChangesActivity, wfRunID, changes).Get(ctx, nil)
+	if err != nil {
+		cadence.GetLogger(ctx).Error("Failed to notify order changes", zap.Error(err))
+		return err
+	}
+	return nil
+}
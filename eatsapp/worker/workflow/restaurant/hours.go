@@ -0,0 +1,97 @@
+package restaurant
+
+import (
+	"time"
+
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/restaurant"
+)
+
+// HoursExecutionTimeout is the execution timeout of a single hours
+// workflow run, which spans the wait for the next opening.
+const HoursExecutionTimeout = time.Hour * 24 * 33
+
+// alwaysOpenCheckInterval is how often the opening hours of a restaurant
+// without hours are checked again.
+const alwaysOpenCheckInterval = time.Hour * 24
+
+func init() {
+	cadence.RegisterWorkflow(HoursWorkflow)
+}
+
+// HoursWorkflow opens the order intake of the restaurant when it opens and
+// closes it when it closes. Every run handles a single day of opening hours
+// and continues as new for the next one.
+func HoursWorkflow(ctx cadence.Context) error {
+
+	ao := cadence.ActivityOptions{
+		ScheduleToStartTimeout: time.Minute * 5,
+		StartToCloseTimeout:    time.Minute * 5,
+	}
+	ctx = cadence.WithActivityOptions(ctx, ao)
+	logger := cadence.GetLogger(ctx)
+
+	var hours restaurant.OpeningHours
+	err := cadence.ExecuteActivity(ctx, restaurant.GetOpeningHoursActivity).Get(ctx, &hours)
+	if err != nil {
+		logger.Error("Failed to get opening hours", zap.Error(err))
+		return err
+	}
+
+	if hours.AlwaysOpen {
+		if err := setIntake(ctx, true); err != nil {
+			return err
+		}
+		if err := cadence.Sleep(ctx, alwaysOpenCheckInterval); err != nil {
+			return err
+		}
+		return continueHours(ctx)
+	}
+
+	if wait := hours.OpensAt.Sub(cadence.Now(ctx)); wait > 0 {
+		if err := setIntake(ctx, false); err != nil {
+			return err
+		}
+		logger.Info("Restaurant opens", zap.Time("at", hours.OpensAt))
+		if err := cadence.Sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+
+	if err := setIntake(ctx, true); err != nil {
+		return err
+	}
+	logger.Info("Restaurant closes", zap.Time("at", hours.ClosesAt))
+	if wait := hours.ClosesAt.Sub(cadence.Now(ctx)); wait > 0 {
+		if err := cadence.Sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+
+	if err := setIntake(ctx, false); err != nil {
+		return err
+	}
+	// let the webserver clock pass the closing time, so that
+	// the next run reads the next opening interval
+	if err := cadence.Sleep(ctx, time.Minute); err != nil {
+		return err
+	}
+
+	// ContinueAsNew workflow to handle the next opening
+	return continueHours(ctx)
+}
+
+func setIntake(ctx cadence.Context, open bool) error {
+	err := cadence.ExecuteActivity(ctx, restaurant.SetIntakeActivity, open).Get(ctx, nil)
+	if err != nil {
+		cadence.GetLogger(ctx).Error("Failed to set order intake", zap.Error(err))
+	}
+	return err
+}
+
+func continueHours(ctx cadence.Context) error {
+	ctx = cadence.WithExecutionStartToCloseTimeout(ctx, HoursExecutionTimeout)
+	return cadence.NewContinueAsNewError(ctx, HoursWorkflow)
+}
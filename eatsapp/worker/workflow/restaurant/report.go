@@ -0,0 +1,104 @@
+package restaurant
+
+import (
+	"time"
+
+	"go.uber.org/cadence"
+	"go.uber.org/zap"
+
+	"github.com/venkat1109/cadence-codelab/eatsapp/worker/activity/restaurant"
+)
+
+// ReportExecutionTimeout is the execution timeout of a single report workflow run.
+const ReportExecutionTimeout = time.Hour * 26
+
+// ReportScheduleVersion is the version of the DailyReportWorkflow input,
+// incremented whenever the workflow changes in a way the runs in flight
+// cannot replay, so that the workflow can tell them apart.
+const ReportScheduleVersion = 1
+
+// ReportSchedule is the input of the DailyReportWorkflow. Days start at
+// midnight in TimeZone, the restaurant time zone configured on the worker
+// if empty.
+type ReportSchedule struct {
+	Version  int
+	TimeZone string
+}
+
+func init() {
+	cadence.RegisterWorkflow(ReportWorkflow)
+	cadence.RegisterWorkflow(DailyReportWorkflow)
+}
+
+// ReportWorkflow is the sales report workflow of the runs started before
+// DailyReportWorkflow, which it continues as once the day is reported.
+// Its decisions must not change, they are replayed by the runs in flight.
+func ReportWorkflow(ctx cadence.Context) error {
+
+	ao := cadence.ActivityOptions{
+		ScheduleToStartTimeout: time.Minute * 5,
+		StartToCloseTimeout:    time.Minute * 15,
+	}
+	ctx = cadence.WithActivityOptions(ctx, ao)
+
+	now := cadence.Now(ctx)
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	at := day.AddDate(0, 0, 1).Add(restaurant.ReportDelay)
+	if now.Before(day.Add(restaurant.ReportDelay)) {
+		// the report of yesterday is not generated yet
+		day = day.AddDate(0, 0, -1)
+		at = day.AddDate(0, 0, 1).Add(restaurant.ReportDelay)
+	}
+
+	if err := cadence.Sleep(ctx, at.Sub(now)); err != nil {
+		return err
+	}
+	generateReport(ctx, day.Format("2006-01-02"))
+
+	ctx = cadence.WithExecutionStartToCloseTimeout(ctx, ReportExecutionTimeout)
+	return cadence.NewContinueAsNewError(ctx, DailyReportWorkflow, ReportSchedule{Version: ReportScheduleVersion})
+}
+
+// DailyReportWorkflow generates the daily sales report of the restaurant
+// once the day is over. Every run reports a single day and continues as
+// new. The day is resolved by an activity, which loads the time zone.
+func DailyReportWorkflow(ctx cadence.Context, schedule ReportSchedule) error {
+
+	ao := cadence.ActivityOptions{
+		ScheduleToStartTimeout: time.Minute * 5,
+		StartToCloseTimeout:    time.Minute * 15,
+	}
+	ctx = cadence.WithActivityOptions(ctx, ao)
+
+	var day restaurant.ReportDay
+	err := cadence.ExecuteActivity(ctx, restaurant.GetReportDayActivity, schedule.TimeZone).Get(ctx, &day)
+	if err != nil {
+		cadence.GetLogger(ctx).Error("Failed to get report day", zap.String("zone", schedule.TimeZone), zap.Error(err))
+		return err
+	}
+
+	if wait := day.At.Sub(cadence.Now(ctx)); wait > 0 {
+		if err := cadence.Sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+	generateReport(ctx, day.Date)
+
+	// ContinueAsNew workflow to report the next day
+	schedule.Version = ReportScheduleVersion
+	ctx = cadence.WithExecutionStartToCloseTimeout(ctx, ReportExecutionTimeout)
+	return cadence.NewContinueAsNewError(ctx, DailyReportWorkflow, schedule)
+}
+
+// generateReport generates the sales report of the date, formatted as
+// YYYY-MM-DD. A failed report can still be generated from the dashboard.
+func generateReport(ctx cadence.Context, date string) {
+	var report restaurant.SalesReport
+	err := cadence.ExecuteActivity(ctx, restaurant.GenerateReportActivity, date).Get(ctx, &report)
+	if err != nil {
+		cadence.GetLogger(ctx).Error("Failed to generate sales report", zap.String("date", date), zap.Error(err))
+		return
+	}
+	cadence.GetLogger(ctx).Info("Generated sales report", zap.String("date", date),
+		zap.Int("orders", report.Orders), zap.Float64("rejection rate", report.RejectionRate))
+}
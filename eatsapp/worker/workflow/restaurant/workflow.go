@@ -22,12 +22,35 @@ func OrderWorkflow(ctx cadence.Context, wfRunID string, orderID string, items []
 	}
 
 	ctx = cadence.WithActivityOptions(ctx, ao)
-	err := cadence.ExecuteActivity(ctx, restaurant.PlaceOrderActivity, wfRunID, orderID, items).Get(ctx, nil)
+	err := admitOrder(ctx, orderID)
+	if err != nil {
+		return time.Minute * 0, err
+	}
+
+	var status string
+	err = cadence.ExecuteActivity(ctx, restaurant.PlaceOrderActivity, wfRunID, orderID, items).Get(ctx, &status)
 	if err != nil {
 		cadence.GetLogger(ctx).Error("Failed to send order to restaurant", zap.Error(err))
 		return time.Minute * 0, err
 	}
 
+	if status == OrderModified {
+		err = reportOrderChanges(ctx, wfRunID, orderID)
+		if err != nil {
+			return time.Minute * 0, err
+		}
+	}
+
+	// split the order into per-station tickets, the order is marked
+	// ready once every station has bumped its ticket
+	var stations []string
+	err = cadence.ExecuteActivity(ctx, restaurant.SendTicketsActivity, orderID).Get(ctx, &stations)
+	if err != nil {
+		cadence.GetLogger(ctx).Error("Failed to send kitchen tickets", zap.Error(err))
+		return time.Minute * 0, err
+	}
+	cadence.GetLogger(ctx).Info("Sent kitchen tickets", zap.Strings("stations", stations))
+
 	var eta time.Duration
 	err = cadence.ExecuteActivity(ctx, restaurant.EstimateETAActivity, orderID).Get(ctx, &eta)
 	if err != nil {